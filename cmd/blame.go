@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newBlameCommand(handler *handler.Blame) *cli.Command {
+	return &cli.Command{
+		Name:   "blame",
+		Usage:  "Show the commit, author and time of the last change to every key",
+		Action: handler.Handle,
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newBundleCommand(handler *handler.Bundle) *cli.Command {
+	return &cli.Command{
+		Name:  "bundle",
+		Usage: "Move an environment's history without a remote server",
+		Commands: []*cli.Command{
+			{
+				Name:   "create",
+				Usage:  fmt.Sprintf("Write commits to a passphrase-encrypted bundle: %s bundle create --env prod -o prod.jebi", core.AppName),
+				Action: handler.HandleCreate,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "env",
+						Aliases: []string{"e"},
+						Usage:   "Environment to bundle (defaults to the current one)",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "Only bundle the commits after this one, for a checkout that already has it",
+					},
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "File to write the bundle to",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:      "apply",
+				Usage:     "Create a checkout from a bundle, or fast-forward an existing one",
+				ArgsUsage: "FILE",
+				Action:    handler.HandleApply,
+			},
+		},
+	}
+}
@@ -13,10 +13,13 @@ func newCommitCommand(handler *handler.Commit) *cli.Command {
 		Usage: fmt.Sprintf("Create a commit with a message: %s commit -m 'message'", AppName),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "message",
-				Aliases:  []string{"m"},
-				Usage:    "Commit message",
-				Required: true,
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Commit message (optional with --amend)",
+			},
+			&cli.BoolFlag{
+				Name:  "amend",
+				Usage: "Fold pending changes, or a new message, into the last unpushed commit",
 			},
 		},
 		Action: handler.Handle,
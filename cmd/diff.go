@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newDiffCommand(handler *handler.Diff) *cli.Command {
+	return &cli.Command{
+		Name:  "diff",
+		Usage: fmt.Sprintf("Show changes between commits, the working state and environments: %s diff [COMMIT_A [COMMIT_B]] | --env ENV", core.AppName),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "env",
+				Usage: "Compare the working state of the current environment with that of ENV",
+			},
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Show decrypted secret values instead of masking them",
+			},
+		},
+		Action: handler.Handle,
+	}
+}
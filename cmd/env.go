@@ -10,7 +10,7 @@ const AppName = "AppName" // change this once, affects all paths
 func newEnvCommand(handler *handler.Env) *cli.Command {
 	return &cli.Command{
 		Name:  "env",
-		Usage: "Manage environments (list, new, use, remove)",
+		Usage: "Manage environments (list, new, use, rename, remove, diff)",
 		Commands: []*cli.Command{
 			{
 				Name:    "list",
@@ -22,11 +22,56 @@ func newEnvCommand(handler *handler.Env) *cli.Command {
 				Name:   "new",
 				Usage:  "Create a new environment",
 				Action: handler.HandleNew,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "Seed the environment with the committed secrets of another environment",
+					},
+					&cli.BoolFlag{
+						Name:  "keys-only",
+						Usage: "With --from, copy key names with empty placeholder values",
+					},
+				},
+			},
+			{
+				Name:      "rename",
+				Usage:     "Rename an environment, keeping its history",
+				ArgsUsage: "OLD NEW",
+				Action:    handler.HandleRename,
+				Aliases:   []string{"mv"},
 			},
 			{
 				Name:   "use",
 				Usage:  "Switch current environment",
 				Action: handler.HandleUse,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "keep",
+						Usage: "Leave uncommitted changes staged in the current environment",
+					},
+					&cli.BoolFlag{
+						Name:  "carry",
+						Usage: "Move uncommitted changes to the new environment",
+					},
+					&cli.BoolFlag{
+						Name:  "stash",
+						Usage: "Stash uncommitted changes before switching",
+					},
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "Compare keys and values across environments without revealing them",
+				ArgsUsage: "[ENV...]",
+				Action:    handler.HandleDiff,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format (table, json)",
+						Value:   "table",
+					},
+				},
 			},
 			{
 				Name:    "remove",
@@ -19,6 +19,10 @@ func newExportCommand(handler *handler.Export) *cli.Command {
 				Usage:   "Output format (env, json, yaml, k8s)",
 				Value:   "env",
 			},
+			&cli.StringFlag{
+				Name:  "at",
+				Usage: "Use the secrets as of a commit or tag instead of the working state",
+			},
 		},
 		Action: handler.Handle,
 	}
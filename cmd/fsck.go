@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newFsckCommand(handler *handler.Fsck) *cli.Command {
+	return &cli.Command{
+		Name:   "fsck",
+		Usage:  fmt.Sprintf("Verify the integrity of history and secrets in every environment: %s fsck", core.AppName),
+		Action: handler.Handle,
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newHistoryCommand(handler *handler.History) *cli.Command {
+	return &cli.Command{
+		Name:      "history",
+		Usage:     fmt.Sprintf("List every committed version of a key: %s history KEY [--reveal]", core.AppName),
+		ArgsUsage: "KEY",
+		Action:    handler.Handle,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Show decrypted values instead of masking them",
+			},
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newKeyCommand(handler *handler.Key) *cli.Command {
+	return &cli.Command{
+		Name:  "key",
+		Usage: "Manage commit signing keys (show, list, trust, untrust)",
+		Commands: []*cli.Command{
+			{
+				Name:   "show",
+				Usage:  "Show your public signing key",
+				Action: handler.HandleShow,
+			},
+			{
+				Name:    "list",
+				Usage:   "List keys trusted to sign commits",
+				Action:  handler.HandleList,
+				Aliases: []string{"ls"},
+			},
+			{
+				Name:   "trust",
+				Usage:  "Trust a public key (defaults to your own key)",
+				Action: handler.HandleTrust,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "name",
+						Aliases: []string{"n"},
+						Usage:   "Name of the key owner",
+					},
+				},
+			},
+			{
+				Name:    "untrust",
+				Usage:   "Stop trusting a public key",
+				Action:  handler.HandleUntrust,
+				Aliases: []string{"rm"},
+			},
+		},
+	}
+}
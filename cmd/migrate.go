@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newMigrateCommand(handler *handler.Migrate) *cli.Command {
+	return &cli.Command{
+		Name:   "migrate",
+		Usage:  "Upgrade the project's on-disk layout to the version this binary uses",
+		Action: handler.Handle,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "List the pending migrations without applying them",
+			},
+		},
+	}
+}
+
+// migrateLayout brings the project layout up to date before a command runs, and
+// refuses to run commands on layouts written by a newer binary. `migrate` does
+// its own work, and `version` and help run on any layout.
+func migrateLayout(workingDir, command string) error {
+	name, _, _ := strings.Cut(command, " ")
+	switch name {
+	case "", "version", "help", "migrate":
+		return nil
+	}
+
+	applied, err := core.NewMigrationService(workingDir).Migrate()
+	for _, migration := range applied {
+		fmt.Fprintf(os.Stderr, "Migrated project layout to version %d: %s\n", migration.Version, migration.Description)
+	}
+	return err
+}
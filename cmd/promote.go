@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newPromoteCommand(handler *handler.Promote) *cli.Command {
+	return &cli.Command{
+		Name:      "promote",
+		Usage:     fmt.Sprintf("Copy committed secrets between environments: %s promote staging prod KEY... | --all", core.AppName),
+		ArgsUsage: "SRC DST [KEY...]",
+		Action:    handler.Handle,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Promote every key of the source environment",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would change without staging anything",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Stage the changes without asking for confirmation",
+			},
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Show decrypted values in the diff",
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+// mergeFlags control how keys changed both locally and on the remote are resolved
+func mergeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "ours",
+			Usage: "Resolve conflicting keys with the local value",
+		},
+		&cli.BoolFlag{
+			Name:  "theirs",
+			Usage: "Resolve conflicting keys with the remote value",
+		},
+		&cli.StringFlag{
+			Name:    "message",
+			Aliases: []string{"m"},
+			Usage:   "Message of the merge commit",
+		},
+	}
+}
+
+func newPullCommand(handler *handler.Pull) *cli.Command {
+	return &cli.Command{
+		Name:   "pull",
+		Usage:  fmt.Sprintf("Fetch remote commits and fast-forward to them: %s pull [--merge|--rebase]", core.AppName),
+		Action: handler.Handle,
+		Flags: append(mergeFlags(),
+			&cli.BoolFlag{
+				Name:  "merge",
+				Usage: "Merge diverged histories with a merge commit instead of stopping",
+			},
+			&cli.BoolFlag{
+				Name:  "rebase",
+				Usage: "Replay local commits on top of the remote instead of merging",
+			},
+			&cli.BoolFlag{
+				Name:  "continue",
+				Usage: "Continue a rebase after resolving conflicting keys",
+			},
+			&cli.BoolFlag{
+				Name:  "abort",
+				Usage: "Abort a rebase and restore the previous history",
+			},
+		),
+	}
+}
+
+func newFetchCommand(handler *handler.Pull) *cli.Command {
+	return &cli.Command{
+		Name:   "fetch",
+		Usage:  fmt.Sprintf("Download remote commits without changing local secrets: %s fetch", core.AppName),
+		Action: handler.HandleFetch,
+	}
+}
+
+func newMergeCommand(handler *handler.Pull) *cli.Command {
+	return &cli.Command{
+		Name:   "merge",
+		Usage:  fmt.Sprintf("Merge fetched remote commits into the local history: %s merge [--ours|--theirs]", core.AppName),
+		Action: handler.HandleMerge,
+		Flags:  mergeFlags(),
+	}
+}
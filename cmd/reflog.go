@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newReflogCommand(handler *handler.Reflog) *cli.Command {
+	return &cli.Command{
+		Name:   "reflog",
+		Usage:  "List recorded operations with their effect on HEADs and pending changes",
+		Action: handler.HandleList,
+	}
+}
+
+func newUndoCommand(handler *handler.Reflog) *cli.Command {
+	return &cli.Command{
+		Name:   "undo",
+		Usage:  fmt.Sprintf("Restore the state before the last operation; repeat to go further back (see %s reflog)", core.AppName),
+		Action: handler.HandleUndo,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Undo even if the workspace changed since the operation",
+			},
+		},
+	}
+}
+
+// unrecordedCommands manage the reflog themselves or cannot change the workspace
+var unrecordedCommands = map[string]bool{
+	"undo":    true,
+	"reflog":  true,
+	"run":     true,
+	"version": true,
+}
+
+// operationRecorder snapshots the workspace before a command runs and appends
+// a reflog entry afterwards when the command changed it
+type operationRecorder struct {
+	reflog   reflogRecorder
+	args     []string
+	before   core.Snapshot
+	captured bool
+}
+
+type reflogRecorder interface {
+	Snapshot() (core.Snapshot, error)
+	Record(command string, before, after core.Snapshot, undoes int) (*core.ReflogEntry, error)
+}
+
+func (r *operationRecorder) Before(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	path := commandPath(cmd, r.args)
+	if path == "" || unrecordedCommands[strings.Fields(path)[0]] {
+		return ctx, nil
+	}
+
+	snapshot, err := r.reflog.Snapshot()
+	if err != nil {
+		// A damaged workspace should not block the command, only its reflog entry
+		return ctx, nil
+	}
+	r.before, r.captured = snapshot, true
+	return ctx, nil
+}
+
+func (r *operationRecorder) After(ctx context.Context, cmd *cli.Command) error {
+	if !r.captured {
+		return nil
+	}
+	after, err := r.reflog.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to record operation in the reflog: %w", err)
+	}
+	if _, err := r.reflog.Record(commandPath(cmd, r.args), r.before, after, 0); err != nil {
+		return fmt.Errorf("failed to record operation in the reflog: %w", err)
+	}
+	return nil
+}
+
+// commandPath returns the names of the subcommands invoked by args, e.g.
+// "env use". Arguments and flag values are left out so secrets never reach the reflog.
+func commandPath(root *cli.Command, args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	var names []string
+	current := root
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		sub := current.Command(arg)
+		if sub == nil {
+			break
+		}
+		names = append(names, sub.Name)
+		current = sub
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newResetCommand(handler *handler.Reset) *cli.Command {
+	return &cli.Command{
+		Name:  "reset",
+		Usage: fmt.Sprintf("Discard all uncommitted changes in the current environment: %s reset [--hard]", core.AppName),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "hard",
+				Aliases: []string{"yes", "y"},
+				Usage:   "Discard changes without asking for confirmation",
+			},
+		},
+		Action: handler.Handle,
+	}
+}
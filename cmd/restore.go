@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newRestoreCommand(handler *handler.Restore) *cli.Command {
+	return &cli.Command{
+		Name:   "restore",
+		Usage:  fmt.Sprintf("Discard uncommitted changes to specific secrets: %s restore KEY...", core.AppName),
+		Action: handler.Handle,
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newRevertCommand(handler *handler.Revert) *cli.Command {
+	return &cli.Command{
+		Name:  "revert",
+		Usage: fmt.Sprintf("Create a commit that undoes the changes of an earlier commit: %s revert COMMIT", core.AppName),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Commit message (defaults to 'Revert \"<original message>\"')",
+			},
+		},
+		Action: handler.Handle,
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newRollbackCommand(handler *handler.Rollback) *cli.Command {
+	return &cli.Command{
+		Name:      "rollback",
+		Usage:     fmt.Sprintf("Restore a single key to an earlier value: %s rollback KEY --to COMMIT|VERSION", core.AppName),
+		ArgsUsage: "KEY",
+		Action:    handler.Handle,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Commit, tag or version number (as shown by history) to take the value from",
+			},
+		},
+	}
+}
@@ -15,31 +15,54 @@ import (
 
 func initializeCommands() []*cli.Command {
 	workingDir := getWorkingDir()
-	appService := core.NewAppService(workingDir)
-	projectService := core.NewProjectService(workingDir)
-	envService := core.NewEnvService(workingDir)
+	store := core.ProjectStore(workingDir)
+	appService := core.NewAppServiceWithStore(store)
+	projectService := core.NewProjectServiceWithStore(store)
+	envService := core.NewEnvServiceWithStore(store)
 	cryptService := crypt.NewService(workingDir)
-	secretService := core.NewSecretService(workingDir)
-	commitService := core.NewCommitService(workingDir)
-	changeRecordService := core.NewChangeRecordService(workingDir)
+	secretService := core.NewSecretServiceWithStore(store)
+	signingService := core.NewSigningService(workingDir)
+	commitService := core.NewCommitServiceWithStore(store, signingService)
+	changeRecordService := core.NewChangeRecordServiceWithStore(store)
+	stashService := core.NewStashServiceWithStore(store)
 	userService := core.NewUserService(workingDir)
+	reflogService := core.NewReflogServiceWithStore(store)
+	migrationService := core.NewMigrationServiceWithStore(store)
 
 	slate := ui.NewSlate(lipgloss.Color("82"))
 
 	setHandler := handler.NewSetHandler(projectService, cryptService, envService, secretService, changeRecordService, slate)
 	addHandler := handler.NewAddHandler(projectService, cryptService, envService, secretService, changeRecordService, slate)
 	removeHandler := handler.NewRemoveHandler(cryptService, envService, secretService, changeRecordService, slate)
-	projectHandler := handler.NewInitHandler(appService, projectService, envService, cryptService, slate)
-	envHandler := handler.NewEnvHandler(envService, slate)
+	projectHandler := handler.NewInitHandler(appService, projectService, envService, cryptService, signingService, userService, slate)
+	envHandler := handler.NewEnvHandler(envService, commitService, secretService, changeRecordService, stashService, projectService, cryptService, slate)
 	commitHandler := handler.NewCommitHandler(envService, commitService, changeRecordService, userService, secretService, projectService, slate)
-	exportHandler := handler.NewExportHandler(envService, cryptService, projectService, slate)
-	statusHandler := handler.NewStatusHandler(envService, slate)
-	runHandler := handler.NewRunHandler(envService, cryptService, projectService, slate)
-	logHandler := handler.NewLogHandler(envService, commitService, slate)
+	exportHandler := handler.NewExportHandler(envService, commitService, cryptService, projectService, slate)
+	statusHandler := handler.NewStatusHandler(envService, commitService, changeRecordService, slate)
+	runHandler := handler.NewRunHandler(envService, commitService, cryptService, projectService, slate)
+	logHandler := handler.NewLogHandler(envService, commitService, projectService, slate)
 	loginHandler := handler.NewLoginHandler(userService, slate)
 	apiClient := remote.NewAPIClient(core.DefaultServerURL)
 	pushHandler := handler.NewPushHandler(projectService, envService, secretService, commitService, cryptService, apiClient, slate)
 	cloneHandler := handler.NewCloneHandler(projectService, envService, secretService, commitService, cryptService, apiClient, slate, appService)
+	pullHandler := handler.NewPullHandler(projectService, envService, secretService, commitService, changeRecordService, cryptService, userService, apiClient, slate)
+	diffHandler := handler.NewDiffHandler(envService, commitService, secretService, projectService, cryptService, slate)
+	revertHandler := handler.NewRevertHandler(envService, commitService, secretService, userService, slate)
+	resetHandler := handler.NewResetHandler(envService, commitService, secretService, changeRecordService, projectService, slate)
+	restoreHandler := handler.NewRestoreHandler(envService, commitService, secretService, changeRecordService, projectService, slate)
+	showHandler := handler.NewShowHandler(envService, commitService, projectService, cryptService, slate)
+	fsckHandler := handler.NewFsckHandler(envService, commitService, secretService, changeRecordService, projectService, cryptService, slate)
+	keyHandler := handler.NewKeyHandler(projectService, signingService, userService, slate)
+	stashHandler := handler.NewStashHandler(envService, commitService, secretService, changeRecordService, stashService, slate)
+	tagHandler := handler.NewTagHandler(envService, commitService, slate)
+	blameHandler := handler.NewBlameHandler(envService, commitService, changeRecordService, slate)
+	historyHandler := handler.NewHistoryHandler(envService, commitService, projectService, cryptService, slate)
+	rollbackHandler := handler.NewRollbackHandler(envService, commitService, secretService, changeRecordService, projectService, slate)
+	promoteHandler := handler.NewPromoteHandler(envService, commitService, secretService, changeRecordService, projectService, cryptService, slate)
+	squashHandler := handler.NewSquashHandler(envService, commitService, userService, slate)
+	reflogHandler := handler.NewReflogHandler(reflogService, commitService, secretService, slate)
+	migrateHandler := handler.NewMigrateHandler(migrationService, slate)
+	bundleHandler := handler.NewBundleHandler(projectService, envService, commitService, cryptService, appService, cloneHandler, pullHandler, slate)
 
 	return []*cli.Command{
 		newInitCommand(projectHandler),
@@ -56,19 +79,64 @@ func initializeCommands() []*cli.Command {
 		newPushCommand(pushHandler),
 		newVersionCommand(),
 		newCloneCommand(cloneHandler),
+		newPullCommand(pullHandler),
+		newFetchCommand(pullHandler),
+		newMergeCommand(pullHandler),
+		newDiffCommand(diffHandler),
+		newRevertCommand(revertHandler),
+		newResetCommand(resetHandler),
+		newRestoreCommand(restoreHandler),
+		newShowCommand(showHandler),
+		newFsckCommand(fsckHandler),
+		newKeyCommand(keyHandler),
+		newStashCommand(stashHandler),
+		newTagCommand(tagHandler),
+		newBlameCommand(blameHandler),
+		newHistoryCommand(historyHandler),
+		newRollbackCommand(rollbackHandler),
+		newPromoteCommand(promoteHandler),
+		newSquashCommand(squashHandler),
+		newReflogCommand(reflogHandler),
+		newUndoCommand(reflogHandler),
+		newMigrateCommand(migrateHandler),
+		newBundleCommand(bundleHandler),
 	}
 }
 
 func Run(ctx context.Context, args []string) error {
+	recorder := &operationRecorder{
+		reflog: core.NewReflogService(getWorkingDir()),
+		args:   args,
+	}
+
 	cmd := &cli.Command{
 		Name:        core.AppName,
 		Usage:       "A demo CLI built with urfave/cli/v3",
 		Description: "Dummy description for CLI",
 		Version:     "0.1.0",
 		Commands:    initializeCommands(),
+		Before:      recorder.Before,
+		After:       recorder.After,
 	}
 
-	return cmd.Run(ctx, args)
+	workingDir := getWorkingDir()
+	command := commandPath(cmd, args)
+	closeWorkspace, err := openWorkspace(workingDir, command)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateLayout(workingDir, command); err != nil {
+		closeWorkspace(err)
+		return err
+	}
+
+	ctx = handler.WithWorkspaceRelease(ctx, func() error { return closeWorkspace(nil) })
+	runErr := cmd.Run(ctx, args)
+	if err := closeWorkspace(runErr); err != nil && runErr == nil {
+		return err
+	}
+	return runErr
 }
 
 func getWorkingDir() string {
@@ -13,5 +13,11 @@ func newRunCommand(handler *handler.Run) *cli.Command {
 		Name:   "run",
 		Usage:  fmt.Sprintf("Run a command in the current environment: %s run -- <command> [args...]", core.AppName),
 		Action: handler.Handle,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "at",
+				Usage: "Use the secrets as of a commit or tag instead of the working state",
+			},
+		},
 	}
 }
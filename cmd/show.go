@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newShowCommand(handler *handler.Show) *cli.Command {
+	return &cli.Command{
+		Name:  "show",
+		Usage: fmt.Sprintf("Show a commit with its changes: %s show COMMIT [--state] [--reveal]", core.AppName),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "state",
+				Usage: "Also show the full set of secrets as of the commit",
+			},
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Show decrypted secret values instead of masking them",
+			},
+		},
+		Action: handler.Handle,
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newSquashCommand(handler *handler.Squash) *cli.Command {
+	return &cli.Command{
+		Name:      "squash",
+		Usage:     fmt.Sprintf("Combine unpushed commits FROM through TO into one: %s squash FROM..TO", core.AppName),
+		ArgsUsage: "FROM..[TO]",
+		Action:    handler.Handle,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Message of the combined commit (defaults to the squashed messages)",
+			},
+		},
+	}
+}
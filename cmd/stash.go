@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newStashCommand(handler *handler.Stash) *cli.Command {
+	return &cli.Command{
+		Name:  "stash",
+		Usage: "Shelve uncommitted changes (push, list, pop, drop)",
+		Commands: []*cli.Command{
+			{
+				Name:   "push",
+				Usage:  "Save pending changes and restore the committed state",
+				Action: handler.HandlePush,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "message",
+						Aliases: []string{"m"},
+						Usage:   "Description of the stashed changes",
+					},
+				},
+			},
+			{
+				Name:    "list",
+				Usage:   "List stash entries",
+				Action:  handler.HandleList,
+				Aliases: []string{"ls"},
+			},
+			{
+				Name:   "pop",
+				Usage:  "Re-apply a stash entry and remove it from the stash",
+				Action: handler.HandlePop,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Apply even if keys changed since the changes were stashed",
+					},
+				},
+			},
+			{
+				Name:   "drop",
+				Usage:  "Discard a stash entry",
+				Action: handler.HandleDrop,
+			},
+		},
+	}
+}
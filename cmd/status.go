@@ -11,7 +11,13 @@ import (
 func newStatusCommand(handler *handler.Status) *cli.Command {
 	return &cli.Command{
 		Name:   "status",
-		Usage:  fmt.Sprintf("Show the status of the current environment: %s status", core.AppName),
+		Usage:  fmt.Sprintf("Show the status of the current environment: %s status [--all]", core.AppName),
 		Action: handler.Handle,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Show pending changes in every environment",
+			},
+		},
 	}
 }
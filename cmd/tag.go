@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/handler"
+	"github.com/urfave/cli/v3"
+)
+
+func newTagCommand(handler *handler.Tag) *cli.Command {
+	return &cli.Command{
+		Name:      "tag",
+		Usage:     fmt.Sprintf("Name a commit so it can be referenced later: %s tag v2.3 [COMMIT]", core.AppName),
+		ArgsUsage: "NAME [COMMIT]",
+		Action:    handler.Handle,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Move an existing tag to the given commit",
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:    "list",
+				Usage:   "List tags of the current environment",
+				Action:  handler.HandleList,
+				Aliases: []string{"ls"},
+			},
+			{
+				Name:    "delete",
+				Usage:   "Delete a tag",
+				Action:  handler.HandleDelete,
+				Aliases: []string{"rm"},
+			},
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+// workspaceLockEnv is set for child processes, such as those started by `run`,
+// to the project directory whose lock this process holds. A nested invocation
+// on the same project then runs inside the parent's lock instead of waiting
+// for it forever.
+const workspaceLockEnv = "JEBI_WORKSPACE_LOCK"
+
+// readOnlyCommands never write to the workspace, so any number of them may run
+// at once under a shared lock
+var readOnlyCommands = map[string]bool{
+	"":           true,
+	"help":       true,
+	"version":    true,
+	"status":     true,
+	"log":        true,
+	"show":       true,
+	"diff":       true,
+	"export":     true,
+	"history":    true,
+	"blame":      true,
+	"fsck":       true,
+	"reflog":     true,
+	"run":        true,
+	"env list":   true,
+	"env diff":   true,
+	"tag list":   true,
+	"stash list": true,
+	"key show":   true,
+	"key list":   true,
+}
+
+// openWorkspace locks the project directory for the whole command, rolls back
+// any operation an earlier invocation left half-done and journals the writes of
+// this one. Read-only commands share the lock and are not journaled. Outside a
+// project it does nothing. The returned function commits the journal, or rolls
+// it back when the command failed, and releases the lock; calling it again does
+// nothing.
+func openWorkspace(workingDir, command string) (func(runErr error) error, error) {
+	noop := func(error) error { return nil }
+	appDir := filepath.Join(workingDir, fmt.Sprintf(".%s", core.AppName))
+	if info, err := os.Stat(appDir); err != nil || !info.IsDir() {
+		return noop, nil
+	}
+	if os.Getenv(workspaceLockEnv) == appDir {
+		return noop, nil
+	}
+
+	lockPath := filepath.Join(appDir, core.LockFileName)
+	journalPath := filepath.Join(appDir, core.JournalFileName)
+	if readOnlyCommands[command] {
+		lock, err := lockWorkspace(lockPath, true)
+		if err != nil {
+			return nil, err
+		}
+		// An interrupted operation or an outdated layout still needs writing
+		if !needsRepair(workingDir, journalPath) {
+			os.Setenv(workspaceLockEnv, appDir)
+			return releaseOnce(func(error) error {
+				os.Unsetenv(workspaceLockEnv)
+				return lock.Unlock()
+			}), nil
+		}
+		if err := lock.Unlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	lock, err := lockWorkspace(lockPath, false)
+	if err != nil {
+		return nil, err
+	}
+	os.Setenv(workspaceLockEnv, appDir)
+
+	restored, err := io.RecoverJournal(journalPath)
+	if err != nil {
+		lock.Unlock()
+		return nil, fmt.Errorf("failed to recover from an interrupted operation: %w", err)
+	}
+	if restored > 0 {
+		fmt.Fprintf(os.Stderr, "Rolled back an interrupted operation (%d change(s) undone)\n", restored)
+	}
+
+	if err := io.BeginJournal(journalPath); err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	return releaseOnce(func(runErr error) error {
+		defer os.Unsetenv(workspaceLockEnv)
+
+		// A failed command leaves the workspace as it found it, unless it
+		// stopped on purpose with state to continue from
+		if runErr != nil && !core.IsStopped(runErr) {
+			if _, err := io.RollbackJournal(); err != nil {
+				lock.Unlock()
+				return fmt.Errorf("failed to roll back the failed command: %w", err)
+			}
+			return lock.Unlock()
+		}
+		if err := io.CommitJournal(); err != nil {
+			lock.Unlock()
+			return err
+		}
+		return lock.Unlock()
+	}), nil
+}
+
+// lockWorkspace acquires the workspace lock, telling the user when it has to
+// wait for another command
+func lockWorkspace(lockPath string, shared bool) (*io.Lock, error) {
+	try, wait := io.TryLock, io.WaitLock
+	if shared {
+		try, wait = io.TryLockShared, io.WaitLockShared
+	}
+
+	lock, err := try(lockPath)
+	if errors.Is(err, io.ErrLocked) {
+		fmt.Fprintf(os.Stderr, "Waiting for another %s command to finish...\n", core.AppName)
+		lock, err = wait(lockPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the workspace: %w", err)
+	}
+	return lock, nil
+}
+
+// needsRepair reports whether the workspace has an interrupted operation to
+// roll back or layout migrations to apply
+func needsRepair(workingDir, journalPath string) bool {
+	if _, err := os.Stat(journalPath); err == nil {
+		return true
+	}
+	pending, err := core.NewMigrationService(workingDir).Pending()
+	return err == nil && len(pending) > 0
+}
+
+// releaseOnce makes a workspace closer safe to call more than once
+func releaseOnce(release func(runErr error) error) func(runErr error) error {
+	released := false
+	return func(runErr error) error {
+		if released {
+			return nil
+		}
+		released = true
+		return release(runErr)
+	}
+}
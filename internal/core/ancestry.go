@@ -0,0 +1,166 @@
+package core
+
+import "fmt"
+
+var (
+	ErrUnrelatedHistories = fmt.Errorf("local and remote histories are unrelated")
+)
+
+// commitMap indexes the commits of an environment by ID
+func (s *commitService) commitMap(env string) (map[string]Commit, error) {
+	commits, err := s.loadCommits(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commits: %w", err)
+	}
+	commitMap := make(map[string]Commit, len(commits))
+	for _, commit := range commits {
+		commitMap[commit.ID] = commit
+	}
+	return commitMap, nil
+}
+
+// GetCommitsSinceRemoteHead returns the commits reachable from the local HEAD
+// that are not ancestors of the remote HEAD, parents before children. Ancestry
+// follows ParentID and MergeParentID links, so timestamps play no part. It fails
+// with ErrUnrelatedHistories when the remote HEAD is not in the local history.
+func (s *commitService) GetCommitsSinceRemoteHead(env string) ([]Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commitMap, err := s.commitMap(env)
+	if err != nil {
+		return nil, err
+	}
+
+	pushed := make(map[string]bool)
+	if head.RemoteHead != "" {
+		if _, exists := commitMap[head.RemoteHead]; !exists {
+			return nil, fmt.Errorf("%w: remote HEAD %s is missing from the local history of '%s'", ErrUnrelatedHistories, head.RemoteHead, env)
+		}
+		if !ancestors(commitMap, head.LocalHead)[head.RemoteHead] {
+			return nil, fmt.Errorf("%w: remote HEAD %s is not an ancestor of local HEAD %s", ErrUnrelatedHistories, head.RemoteHead, head.LocalHead)
+		}
+		pushed = ancestors(commitMap, head.RemoteHead)
+	}
+
+	return topoOrder(commitMap, head.LocalHead, pushed)
+}
+
+// CommitsSince returns the commits reachable from the local HEAD that are not
+// ancestors of baseID, parents before children; an empty baseID returns the
+// whole history. It fails with ErrUnrelatedHistories when baseID is not an
+// ancestor of the local HEAD.
+func (s *commitService) CommitsSince(env, baseID string) ([]Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commitMap, err := s.commitMap(env)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make(map[string]bool)
+	if baseID != "" {
+		if !ancestors(commitMap, head.LocalHead)[baseID] {
+			return nil, fmt.Errorf("%w: %s is not an ancestor of local HEAD %s", ErrUnrelatedHistories, baseID, head.LocalHead)
+		}
+		exclude = ancestors(commitMap, baseID)
+	}
+
+	return topoOrder(commitMap, head.LocalHead, exclude)
+}
+
+// AheadBehind counts the local commits the remote does not have yet and the
+// fetched remote commits not yet integrated into the local history. Behind
+// reflects the last fetch.
+func (s *commitService) AheadBehind(env string) (ahead, behind int, err error) {
+	local, err := s.GetCommitsSinceRemoteHead(env)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fetched, err := s.GetFetched(env)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fetched.Head == "" {
+		return len(local), 0, nil
+	}
+
+	head, err := s.GetHead(env)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commitMap, err := s.commitMap(env)
+	if err != nil {
+		return 0, 0, err
+	}
+	integrated := ancestors(commitMap, head.LocalHead)
+	for _, commit := range fetched.Commits {
+		if !integrated[commit.ID] {
+			behind++
+		}
+	}
+	return len(local), behind, nil
+}
+
+// topoOrder returns the commits reachable from headID through both parents,
+// stopping at excluded commits. Every commit comes after its parents; first
+// parents are visited before merge parents.
+func topoOrder(commitMap map[string]Commit, headID string, exclude map[string]bool) ([]Commit, error) {
+	var ordered []Commit
+	visited := make(map[string]bool)
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		if id == "" || visited[id] || exclude[id] {
+			return nil
+		}
+		visited[id] = true
+
+		commit, exists := commitMap[id]
+		if !exists {
+			return fmt.Errorf("%w: %s is referenced but missing from the history", ErrCommitNotFound, id)
+		}
+		if err := visit(commit.ParentID); err != nil {
+			return err
+		}
+		if err := visit(commit.MergeParentID); err != nil {
+			return err
+		}
+		ordered = append(ordered, commit)
+		return nil
+	}
+
+	if err := visit(headID); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// ListHistory returns the commits reachable from the local HEAD, newest first.
+// Commits left behind by amend, squash or rebase are not included.
+func (s *commitService) ListHistory(env string) ([]Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commitMap, err := s.commitMap(env)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered, err := topoOrder(commitMap, head.LocalHead, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	}
+	return ordered, nil
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitIDs(commits []Commit) []string {
+	ids := make([]string, len(commits))
+	for i, commit := range commits {
+		ids[i] = commit.ID
+	}
+	return ids
+}
+
+func TestGetCommitsSinceRemoteHead(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	now := time.Now()
+	// Timestamps are skewed on purpose: ancestry, not time, decides what is new
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "root", Timestamp: now},
+		{ID: "pushed", ParentID: "root", Timestamp: now.Add(time.Hour)},
+		{ID: "remote", ParentID: "pushed", Timestamp: now.Add(-time.Hour)},
+		{ID: "local", ParentID: "pushed", Timestamp: now.Add(-2 * time.Hour)},
+		{ID: "merge", ParentID: "local", MergeParentID: "remote", Timestamp: now.Add(-3 * time.Hour)},
+		{ID: "orphan", ParentID: "root", Timestamp: now.Add(2 * time.Hour)},
+	}))
+
+	require.NoError(t, svc.UpdateLocalHead("dev", "merge"))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "remote"))
+	commits, err := svc.GetCommitsSinceRemoteHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local", "merge"}, commitIDs(commits), "Commits the remote has should not be pushed again")
+
+	require.NoError(t, svc.UpdateRemoteHead("dev", ""))
+	commits, err = svc.GetCommitsSinceRemoteHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "pushed", "local", "remote", "merge"}, commitIDs(commits), "Parents should come before children")
+
+	require.NoError(t, svc.UpdateRemoteHead("dev", "orphan"))
+	_, err = svc.GetCommitsSinceRemoteHead("dev")
+	assert.ErrorIs(t, err, ErrUnrelatedHistories, "A remote HEAD off the local history should be rejected")
+
+	require.NoError(t, svc.UpdateRemoteHead("dev", "missing"))
+	_, err = svc.GetCommitsSinceRemoteHead("dev")
+	assert.ErrorIs(t, err, ErrUnrelatedHistories)
+}
+
+func TestAheadBehind(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "base"},
+		{ID: "local", ParentID: "base"},
+	}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "local"))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "base"))
+
+	ahead, behind, err := svc.AheadBehind("dev")
+	require.NoError(t, err)
+	assert.Equal(t, 1, ahead)
+	assert.Equal(t, 0, behind)
+
+	require.NoError(t, svc.SaveFetched("dev", FetchedCommits{
+		BaseCommit: "base",
+		Head:       "remote2",
+		Commits: []Commit{
+			{ID: "remote1", ParentID: "base"},
+			{ID: "remote2", ParentID: "remote1"},
+		},
+	}))
+	ahead, behind, err = svc.AheadBehind("dev")
+	require.NoError(t, err)
+	assert.Equal(t, 1, ahead)
+	assert.Equal(t, 2, behind)
+}
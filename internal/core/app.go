@@ -1,32 +1,67 @@
 package core
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+
+	"github.com/jawahars16/jebi/internal/io"
 )
 
+// stoppedError is a failure a command reports after deliberately saving state
+// for the user to continue from
+type stoppedError struct {
+	err error
+}
+
+func (e stoppedError) Error() string { return e.err.Error() }
+func (e stoppedError) Unwrap() error { return e.err }
+
+// Stopped marks err as a deliberate stop, such as a rebase halted on a
+// conflict, so the writes the command made before failing are kept
+func Stopped(err error) error {
+	return stoppedError{err: err}
+}
+
+// IsStopped reports whether err marks a deliberate stop
+func IsStopped(err error) bool {
+	var stopped stoppedError
+	return errors.As(err, &stopped)
+}
+
 type appService struct {
-	workingDir string
+	store io.Store
 }
 
 func NewAppService(workingDir string) *appService {
+	return NewAppServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewAppServiceWithStore creates an app service for the project held by store
+func NewAppServiceWithStore(store io.Store) *appService {
 	return &appService{
-		workingDir: workingDir,
+		store: store,
 	}
 }
 
 func (s *appService) CreateAppDir() error {
-	dirName := filepath.Join(s.workingDir, fmt.Sprintf(".%s", AppName))
-	info, err := os.Stat(dirName)
-	if err == nil && info.IsDir() {
+	dirName := fmt.Sprintf(".%s", AppName)
+	info, err := s.store.Stat("")
+	if err == nil && info.IsDir {
 		// Directory exists; check if it's empty
-		entries, err := os.ReadDir(dirName)
+		entries, err := s.store.ReadDir("")
 		if err != nil {
 			return fmt.Errorf("failed to read directory contents: %w", err)
 		}
 
-		if len(entries) > 0 {
+		// The workspace lock and journal may exist before the project does
+		used := 0
+		for _, entry := range entries {
+			if entry.Name != LockFileName && entry.Name != JournalFileName {
+				used++
+			}
+		}
+		if used > 0 {
 			return fmt.Errorf(
 				"project already initialized in %q. Use a different directory or remove %q to reinitialize",
 				dirName, dirName,
@@ -34,29 +69,30 @@ func (s *appService) CreateAppDir() error {
 		}
 
 		// Directory exists but is empty — fine to reuse
-		return nil
+		return writeLayoutVersion(s.store, LayoutVersion)
 	}
 
-	if os.IsNotExist(err) {
+	if errors.Is(err, fs.ErrNotExist) {
 		// Directory doesn't exist, create it
-		if err := os.Mkdir(dirName, 0755); err != nil {
+		if err := s.store.MkdirAll(""); err != nil {
 			return fmt.Errorf("failed to create directory %q: %w", dirName, err)
 		}
-		return nil
+		return writeLayoutVersion(s.store, LayoutVersion)
 	}
 
-	// Some other error
+	if err == nil {
+		return fmt.Errorf("%q exists but is not a directory", dirName)
+	}
 	return fmt.Errorf("failed to check directory %q: %w", dirName, err)
 }
 
 func (s *appService) Exists() (bool, error) {
-	dirName := filepath.Join(s.workingDir, fmt.Sprintf(".%s", AppName))
-	info, err := os.Stat(dirName)
+	info, err := s.store.Stat("")
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return false, nil
 		}
-		return false, fmt.Errorf("failed to check directory %q: %w", dirName, err)
+		return false, fmt.Errorf("failed to check directory %q: %w", fmt.Sprintf(".%s", AppName), err)
 	}
-	return info.IsDir(), nil
+	return info.IsDir, nil
 }
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidBundle    = fmt.Errorf("not a valid bundle")
+	ErrIncompleteBundle = fmt.Errorf("the bundle does not connect to this history")
+)
+
+const (
+	BundleFormat  = "jebi-bundle"
+	BundleVersion = 1
+)
+
+// Bundle packages the history of one environment so it can be moved without a
+// remote server. Commits after BaseCommit are listed parents first; an empty
+// BaseCommit means the bundle holds the whole history.
+type Bundle struct {
+	Format      string      `json:"format"`
+	Version     int         `json:"version"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	Project     Project     `json:"project"` // Including the encryption key
+	Environment Environment `json:"environment"`
+	BaseCommit  string      `json:"baseCommit,omitempty"`
+	Head        string      `json:"head"`
+	Commits     []Commit    `json:"commits"`
+	Tags        []Tag       `json:"tags,omitempty"`
+	Secrets     []Secret    `json:"secrets,omitempty"` // State at Head; only in whole-history bundles
+}
+
+// Validate checks that a decoded bundle is one this version can apply
+func (b Bundle) Validate() error {
+	switch {
+	case b.Format != BundleFormat:
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidBundle, b.Format)
+	case b.Version > BundleVersion:
+		return fmt.Errorf("%w: version %d is newer than this version of %s supports (%d)", ErrInvalidBundle, b.Version, AppName, BundleVersion)
+	case b.Project.ID == "" || b.Environment.Name == "":
+		return fmt.Errorf("%w: missing project or environment", ErrInvalidBundle)
+	case len(b.Commits) == 0 || b.Commits[len(b.Commits)-1].ID != b.Head:
+		return fmt.Errorf("%w: commits do not end at the bundle head %s", ErrInvalidBundle, b.Head)
+	}
+	return nil
+}
+
+// CommitsAfter returns the bundled commits a history at since is missing,
+// parents first. It fails with ErrIncompleteBundle when the bundle starts
+// after since.
+func (b Bundle) CommitsAfter(since string) ([]Commit, error) {
+	switch since {
+	case b.Head:
+		return nil, nil
+	case b.BaseCommit:
+		return b.Commits, nil
+	}
+
+	// Parents come first, so nothing after since is one of its ancestors
+	for i, commit := range b.Commits {
+		if commit.ID == since {
+			return b.Commits[i+1:], nil
+		}
+	}
+
+	if since == "" {
+		return nil, fmt.Errorf("%w: the bundle starts after commit %s; apply a bundle with the whole history first", ErrIncompleteBundle, b.BaseCommit)
+	}
+	return nil, fmt.Errorf("%w: the history is at %s, but the bundle covers %s..%s", ErrIncompleteBundle, since, orRoot(b.BaseCommit), b.Head)
+}
+
+func orRoot(commitID string) string {
+	if commitID == "" {
+		return "root"
+	}
+	return commitID
+}
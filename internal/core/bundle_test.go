@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitsSince(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "root"},
+		{ID: "base", ParentID: "root"},
+		{ID: "side", ParentID: "root"},
+		{ID: "merge", ParentID: "base", MergeParentID: "side"},
+		{ID: "orphan", ParentID: "root"},
+	}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "merge"))
+
+	commits, err := svc.CommitsSince("dev", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "base", "side", "merge"}, commitIDs(commits))
+
+	commits, err = svc.CommitsSince("dev", "base")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"side", "merge"}, commitIDs(commits), "Merged commits the base does not have should be included")
+
+	_, err = svc.CommitsSince("dev", "orphan")
+	assert.ErrorIs(t, err, ErrUnrelatedHistories)
+}
+
+func TestBundleCommitsAfter(t *testing.T) {
+	bundle := Bundle{
+		Format:      BundleFormat,
+		Version:     BundleVersion,
+		Project:     Project{ID: "p1"},
+		Environment: Environment{Name: "prod"},
+		BaseCommit:  "base",
+		Head:        "c2",
+		Commits:     []Commit{{ID: "c1", ParentID: "base"}, {ID: "c2", ParentID: "c1"}},
+	}
+	require.NoError(t, bundle.Validate())
+
+	commits, err := bundle.CommitsAfter("base")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2"}, commitIDs(commits))
+
+	commits, err = bundle.CommitsAfter("c1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c2"}, commitIDs(commits), "A history partly caught up should get the rest")
+
+	commits, err = bundle.CommitsAfter("c2")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+
+	_, err = bundle.CommitsAfter("")
+	assert.ErrorIs(t, err, ErrIncompleteBundle, "An incremental bundle cannot start a new history")
+	_, err = bundle.CommitsAfter("older")
+	assert.ErrorIs(t, err, ErrIncompleteBundle)
+
+	bundle.Head = "c1"
+	assert.ErrorIs(t, bundle.Validate(), ErrInvalidBundle)
+	bundle.Head, bundle.Version = "c2", BundleVersion+1
+	assert.ErrorIs(t, bundle.Validate(), ErrInvalidBundle)
+}
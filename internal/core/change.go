@@ -2,59 +2,90 @@ package core
 
 import (
 	"fmt"
-	"path/filepath"
+	"sort"
 
 	"github.com/jawahars16/jebi/internal/io"
 )
 
 type changeRecordService struct {
-	workingDir     string
-	currentEnvPath string
+	store io.Store
 }
 
 func NewChangeRecordService(workingDir string) *changeRecordService {
+	return NewChangeRecordServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewChangeRecordServiceWithStore creates a change record service backed by store
+func NewChangeRecordServiceWithStore(store io.Store) *changeRecordService {
 	return &changeRecordService{
-		workingDir:     workingDir,
-		currentEnvPath: filepath.Join(workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, CurrentFileName),
+		store: store,
 	}
 }
 
-func (s *changeRecordService) AddChangeRecord(env, action, key, value, nonce string, noSecret bool) error {
-	curr, err := io.ReadJSONFile[CurrentEnv](s.currentEnvPath)
+// loadPendingChanges reads the uncommitted changes staged in an environment
+func loadPendingChanges(store io.Store, env string) ([]Change, error) {
+	changes, err := io.ReadJSON[[]Change](store, envPath(env, PendingChangesFileName))
 	if err != nil {
-		return fmt.Errorf("failed to read current environment: %w", err)
+		return nil, fmt.Errorf("failed to read pending changes: %w", err)
+	}
+	return changes, nil
+}
+
+// savePendingChanges writes the uncommitted changes staged in an environment
+func savePendingChanges(store io.Store, env string, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+	if err := io.WriteJSON(store, envPath(env, PendingChangesFileName), changes); err != nil {
+		return fmt.Errorf("failed to write pending changes: %w", err)
 	}
-	if curr.Changes == nil {
-		curr.Changes = []Change{}
+	return nil
+}
+
+// GetPendingChanges returns the uncommitted changes staged in an environment
+func (s *changeRecordService) GetPendingChanges(env string) ([]Change, error) {
+	return loadPendingChanges(s.store, env)
+}
+
+func (s *changeRecordService) AddChangeRecord(env, action, key, value, nonce string, noSecret bool) error {
+	changes, err := loadPendingChanges(s.store, env)
+	if err != nil {
+		return err
 	}
-	curr.Changes = append(curr.Changes, Change{
+	changes = append(changes, Change{
 		Type:     ChangeType(action),
 		Key:      key,
 		Value:    value,
 		Nonce:    nonce,
 		NoSecret: noSecret,
 	})
-	curr.Changes = normalizeChanges(curr.Changes)
+	return savePendingChanges(s.store, env, normalizeChanges(changes))
+}
 
-	err = io.WriteJSONToFile(s.currentEnvPath, curr)
-	if err != nil {
-		return fmt.Errorf("failed to write current environment: %w", err)
-	}
-	return nil
+func (s *changeRecordService) ClearPendingChanges(env string) error {
+	return savePendingChanges(s.store, env, []Change{})
 }
 
-func (s *changeRecordService) ClearPendingChanges() error {
-	curr, err := io.ReadJSONFile[CurrentEnv](s.currentEnvPath)
+// DiscardPendingChanges removes the pending changes recorded for the given keys
+func (s *changeRecordService) DiscardPendingChanges(env string, keys []string) error {
+	changes, err := loadPendingChanges(s.store, env)
 	if err != nil {
-		return fmt.Errorf("failed to read current environment: %w", err)
+		return err
 	}
-	curr.Changes = []Change{}
 
-	err = io.WriteJSONToFile(s.currentEnvPath, curr)
-	if err != nil {
-		return fmt.Errorf("failed to write current environment: %w", err)
+	discard := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		discard[key] = true
 	}
-	return nil
+
+	remaining := []Change{}
+	for _, change := range changes {
+		if !discard[change.Key] {
+			remaining = append(remaining, change)
+		}
+	}
+
+	return savePendingChanges(s.store, env, remaining)
 }
 
 // normalizeChanges removes duplicate changes and applies conflict resolution
@@ -100,6 +131,51 @@ func normalizeChanges(changes []Change) []Change {
 	return normalized
 }
 
+// InverseChanges builds the changes that undo a commit on top of the current state.
+// parentState is the state before the commit; keys the commit touched are restored
+// to their parent values, and keys already matching the target are skipped.
+func InverseChanges(commit Commit, parentState, currentState map[string]Secret) []Change {
+	var inverse []Change
+	seen := make(map[string]bool)
+
+	for _, change := range commit.Changes {
+		if seen[change.Key] {
+			continue
+		}
+		seen[change.Key] = true
+
+		target, existedBefore := parentState[change.Key]
+		current, existsNow := currentState[change.Key]
+
+		switch {
+		case !existedBefore && existsNow:
+			inverse = append(inverse, Change{Type: ChangeTypeRemove, Key: change.Key})
+		case existedBefore && !existsNow:
+			inverse = append(inverse, Change{
+				Type:     ChangeTypeAdd,
+				Key:      change.Key,
+				Value:    target.Value,
+				Nonce:    target.Nonce,
+				NoSecret: target.NoSecret,
+			})
+		case existedBefore && existsNow && !sameSecretValue(target, current):
+			inverse = append(inverse, Change{
+				Type:     ChangeTypeModify,
+				Key:      change.Key,
+				Value:    target.Value,
+				Nonce:    target.Nonce,
+				NoSecret: target.NoSecret,
+			})
+		}
+	}
+
+	sort.Slice(inverse, func(i, j int) bool {
+		return inverse[i].Key < inverse[j].Key
+	})
+
+	return inverse
+}
+
 // func normalizeChanges(changes []Change) []Change {
 // 	latest := make(map[string]Change)
 
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInverseChanges(t *testing.T) {
+	parentState := map[string]Secret{
+		"API_KEY":      {Key: "API_KEY", Value: "old", Nonce: "n1"},
+		"DATABASE_URL": {Key: "DATABASE_URL", Value: "db", Nonce: "n2"},
+	}
+	commit := Commit{
+		ID: "abc",
+		Changes: []Change{
+			{Type: ChangeTypeModify, Key: "API_KEY", Value: "new", Nonce: "n3"},
+			{Type: ChangeTypeRemove, Key: "DATABASE_URL"},
+			{Type: ChangeTypeAdd, Key: "REDIS_URL", Value: "redis", Nonce: "n4"},
+		},
+	}
+	currentState := map[string]Secret{
+		"API_KEY":   {Key: "API_KEY", Value: "new", Nonce: "n3"},
+		"REDIS_URL": {Key: "REDIS_URL", Value: "redis", Nonce: "n4"},
+	}
+
+	inverse := InverseChanges(commit, parentState, currentState)
+	require.Len(t, inverse, 3)
+
+	assert.Equal(t, Change{Type: ChangeTypeModify, Key: "API_KEY", Value: "old", Nonce: "n1"}, inverse[0])
+	assert.Equal(t, Change{Type: ChangeTypeAdd, Key: "DATABASE_URL", Value: "db", Nonce: "n2"}, inverse[1])
+	assert.Equal(t, Change{Type: ChangeTypeRemove, Key: "REDIS_URL"}, inverse[2])
+}
+
+func TestInverseChangesSkipsAlreadyRestoredKeys(t *testing.T) {
+	parentState := map[string]Secret{
+		"API_KEY": {Key: "API_KEY", Value: "old", Nonce: "n1"},
+	}
+	commit := Commit{
+		Changes: []Change{{Type: ChangeTypeModify, Key: "API_KEY", Value: "new", Nonce: "n2"}},
+	}
+
+	// A later commit already restored the original value
+	assert.Empty(t, InverseChanges(commit, parentState, parentState))
+}
@@ -1,62 +1,112 @@
 package core
 
 import (
-	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/jawahars16/jebi/internal/io"
 )
 
+var (
+	ErrCommitNotFound  = fmt.Errorf("commit not found")
+	ErrAmbiguousCommit = fmt.Errorf("ambiguous commit reference")
+)
+
 type commitService struct {
-	workingDir string
+	store  io.Store
+	signer CommitSigner
 }
 
 func NewCommitService(workingDir string) *commitService {
+	return NewCommitServiceWithStore(ProjectStore(workingDir), nil)
+}
+
+// NewCommitServiceWithSigner creates a commit service that signs every new commit
+func NewCommitServiceWithSigner(workingDir string, signer CommitSigner) *commitService {
+	return NewCommitServiceWithStore(ProjectStore(workingDir), signer)
+}
+
+// NewCommitServiceWithStore creates a commit service backed by store. The
+// signer may be nil, in which case commits are left unsigned.
+func NewCommitServiceWithStore(store io.Store, signer CommitSigner) *commitService {
 	return &commitService{
-		workingDir: workingDir,
+		store:  store,
+		signer: signer,
 	}
 }
 
-// getCommitsPath returns the path to commits file for an environment
-func (s *commitService) getCommitsPath(env string) string {
-	return filepath.Join(s.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, env, CommitFileName)
+// commitLog returns the commit history store of an environment
+func (s *commitService) commitLog(env string) commitLog {
+	return commitLog{store: s.store, dir: envPath(env)}
 }
 
-// getHeadPath returns the path to HEAD file for an environment
+// getHeadPath returns the store name of the HEAD file for an environment
 func (s *commitService) getHeadPath(env string) string {
-	return filepath.Join(s.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, env, "HEAD")
+	return envPath(env, "HEAD")
 }
 
-// generateCommitID generates a unique commit ID based on timestamp and content
-func (s *commitService) generateCommitID(message string, author string, timestamp time.Time) string {
-	content := fmt.Sprintf("%s-%s-%d", message, author, timestamp.Unix())
-	hash := sha1.Sum([]byte(content))
-	return fmt.Sprintf("%x", hash)[:12] // Use first 12 characters like Git
+// ComputeCommitID derives a content-addressed commit ID from the parent ID and
+// the canonical serialization of the changes, so identical content on the same
+// parent always yields the same ID and any tampering changes it
+func ComputeCommitID(parentID string, changes []Change) string {
+	hash := sha256.New()
+	hash.Write([]byte(parentID))
+	hash.Write([]byte{0})
+	hash.Write(canonicalChanges(changes))
+	return fmt.Sprintf("%x", hash.Sum(nil))[:12] // Use first 12 characters like Git
 }
 
-// AddCommit creates a new commit with the given changes
-func (s *commitService) AddCommit(id, env, message, author string, changes []Change, timestamp time.Time) (*Commit, error) {
-	// Load existing commits
-	commits, err := s.loadCommits(env)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load existing commits: %w", err)
+// CommitContentID returns the content-addressed ID a commit should have,
+// covering the second parent of merge commits and the message of rewritten ones
+func CommitContentID(commit Commit) string {
+	if commit.MergeParentID == "" && commit.Amends == "" {
+		return ComputeCommitID(commit.ParentID, commit.Changes)
 	}
+	hash := sha256.New()
+	hash.Write([]byte(commit.ParentID))
+	hash.Write([]byte{0})
+	if commit.MergeParentID != "" {
+		hash.Write([]byte(commit.MergeParentID))
+		hash.Write([]byte{0})
+	}
+	if commit.Amends != "" {
+		hash.Write([]byte(commit.Amends))
+		hash.Write([]byte{0})
+		hash.Write([]byte(commit.Message))
+		hash.Write([]byte{0})
+	}
+	hash.Write(canonicalChanges(commit.Changes))
+	return fmt.Sprintf("%x", hash.Sum(nil))[:12]
+}
 
+// canonicalChanges serializes changes in a stable order independent of how they were recorded
+func canonicalChanges(changes []Change) []byte {
+	sorted := append([]Change(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Key != sorted[j].Key {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+
+	// Marshalling a slice of plain structs cannot fail
+	data, _ := json.Marshal(sorted)
+	return data
+}
+
+// AddCommit creates a new commit with the given changes
+func (s *commitService) AddCommit(id, env, message, author string, changes []Change, timestamp time.Time) (*Commit, error) {
 	// Get current HEAD to set as parent
 	head, err := s.GetHead(env)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	if id == "" {
-		id = s.generateCommitID(message, author, timestamp)
-	}
-
-	// Create new commit
-	commit := &Commit{
+	commit := Commit{
 		ID:        id,
 		Message:   message,
 		Author:    author,
@@ -64,13 +114,79 @@ func (s *commitService) AddCommit(id, env, message, author string, changes []Cha
 		Changes:   changes,
 		ParentID:  head.LocalHead,
 	}
+	if commit.ID == "" {
+		commit.ID = CommitContentID(commit)
+	}
 
-	// Append to commits list
-	commits = append(commits, *commit)
+	return s.appendCommit(env, commit)
+}
+
+// ReplayCommit re-creates original on top of the local HEAD with changes, as a
+// rebase does. The original author, message and time are kept; committer, who
+// signs the new commit, is recorded when it is someone else.
+func (s *commitService) ReplayCommit(env string, original Commit, committer string, changes []Change) (*Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
 
-	// Save commits
-	if err := s.saveCommits(env, commits); err != nil {
-		return nil, fmt.Errorf("failed to save commits: %w", err)
+	commit := Commit{
+		Message:   original.Message,
+		Author:    original.Author,
+		Timestamp: original.Timestamp,
+		Changes:   changes,
+		ParentID:  head.LocalHead,
+	}
+	if committer != original.Author {
+		commit.Committer = committer
+	}
+	commit.ID = CommitContentID(commit)
+
+	return s.appendCommit(env, commit)
+}
+
+// AddMergeCommit creates a commit on top of the local HEAD with mergeParentID as
+// its second parent. Changes turn the local HEAD state into the merged state.
+func (s *commitService) AddMergeCommit(env, message, author string, changes []Change, mergeParentID string, timestamp time.Time) (*Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit := Commit{
+		Message:       message,
+		Author:        author,
+		Timestamp:     timestamp,
+		Changes:       changes,
+		ParentID:      head.LocalHead,
+		MergeParentID: mergeParentID,
+	}
+	commit.ID = CommitContentID(commit)
+
+	return s.appendCommit(env, commit)
+}
+
+// appendCommit signs a new commit, stores it and moves the local HEAD to it.
+// When the log already holds a commit with the same ID, e.g. the same changes
+// committed again after an undo, that commit is reused instead.
+func (s *commitService) appendCommit(env string, commit Commit) (*Commit, error) {
+	existing, ok, err := s.findCommit(env, commit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if err := s.UpdateLocalHead(env, existing.ID); err != nil {
+			return nil, fmt.Errorf("failed to update HEAD: %w", err)
+		}
+		return existing, nil
+	}
+
+	if err := s.sign(&commit); err != nil {
+		return nil, err
+	}
+
+	if err := s.commitLog(env).append(commit); err != nil {
+		return nil, fmt.Errorf("failed to save commit: %w", err)
 	}
 
 	// Update local HEAD
@@ -78,23 +194,135 @@ func (s *commitService) AddCommit(id, env, message, author string, changes []Cha
 		return nil, fmt.Errorf("failed to update HEAD: %w", err)
 	}
 
-	return commit, nil
+	return &commit, nil
+}
+
+// sign signs a commit when the service has a signer, replacing any earlier signature
+func (s *commitService) sign(commit *Commit) error {
+	commit.Signature, commit.SignerKey = "", ""
+	if s.signer == nil {
+		return nil
+	}
+	signature, signerKey, err := s.signer.Sign(CommitSigningPayload(*commit))
+	if err != nil {
+		return fmt.Errorf("failed to sign commit: %w", err)
+	}
+	commit.Signature = signature
+	commit.SignerKey = signerKey
+	return nil
+}
+
+// ImportCommit stores a commit received from elsewhere (e.g. the remote) as-is,
+// preserving its ID, parent and signature, and moves the local HEAD to it
+func (s *commitService) ImportCommit(env string, commit Commit) (*Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// Older servers do not return parent links; commits arrive in order
+	if commit.ParentID == "" {
+		commit.ParentID = head.LocalHead
+	}
+
+	existing, ok, err := s.findCommit(env, commit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		commit = *existing
+	} else if err := s.commitLog(env).append(commit); err != nil {
+		return nil, fmt.Errorf("failed to save commit: %w", err)
+	}
+
+	if err := s.UpdateLocalHead(env, commit.ID); err != nil {
+		return nil, fmt.Errorf("failed to update HEAD: %w", err)
+	}
+
+	return &commit, nil
+}
+
+// RelabelCommits replaces oldName with env in the environment name recorded on
+// commits, e.g. after the environment was renamed. IDs and signatures do not
+// cover the environment name, so history stays valid.
+func (s *commitService) RelabelCommits(env, oldName string) error {
+	commits, err := s.loadCommits(env)
+	if err != nil {
+		return fmt.Errorf("failed to load commits: %w", err)
+	}
+
+	for i := range commits {
+		if commits[i].EnvironmentName == oldName {
+			commits[i].EnvironmentName = env
+		}
+	}
+	return s.saveCommits(env, commits)
 }
 
 // GetCommit retrieves a specific commit by ID
 func (s *commitService) GetCommit(env, commitID string) (*Commit, error) {
-	commits, err := s.loadCommits(env)
+	commit, ok, err := s.findCommit(env, commitID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("commit %s not found in environment %s", commitID, env)
+	}
+	return commit, nil
+}
+
+// findCommit looks up a commit by full ID; ok is false when there is none
+func (s *commitService) findCommit(env, commitID string) (commit *Commit, ok bool, err error) {
+	reader, err := s.commitLog(env).open()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load commits: %w", err)
+	}
+	found, ok, err := reader.get(commitID)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &found, true, nil
+}
+
+// ResolveCommit retrieves a commit by tag name, full ID or a unique prefix of the ID.
+// Tags take precedence over ID prefixes.
+func (s *commitService) ResolveCommit(env, ref string) (*Commit, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("%w: empty reference", ErrCommitNotFound)
+	}
+
+	if tag, err := s.GetTag(env, ref); err == nil {
+		return s.GetCommit(env, tag.CommitID)
+	}
+
+	reader, err := s.commitLog(env).open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load commits: %w", err)
 	}
 
-	for _, commit := range commits {
-		if commit.ID == commitID {
-			return &commit, nil
+	var matches []string
+	for _, entry := range reader.entries {
+		if entry.ID == ref {
+			matches = []string{ref}
+			break
+		}
+		if strings.HasPrefix(entry.ID, ref) {
+			matches = append(matches, entry.ID)
 		}
 	}
 
-	return nil, fmt.Errorf("commit %s not found in environment %s", commitID, env)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s in environment %s", ErrCommitNotFound, ref, env)
+	case 1:
+		commit, _, err := reader.get(matches[0])
+		if err != nil {
+			return nil, err
+		}
+		return &commit, nil
+	default:
+		return nil, fmt.Errorf("%w: %s matches %s", ErrAmbiguousCommit, ref, strings.Join(matches, ", "))
+	}
 }
 
 // ListCommits returns all commits for an environment, sorted by timestamp (newest first)
@@ -114,7 +342,7 @@ func (s *commitService) ListCommits(env string) ([]Commit, error) {
 
 // GetHead retrieves the HEAD pointers for an environment
 func (s *commitService) GetHead(env string) (*Head, error) {
-	head, err := io.ReadJSONFile[Head](s.getHeadPath(env))
+	head, err := io.ReadJSON[Head](s.store, s.getHeadPath(env))
 	if err != nil {
 		return &Head{}, fmt.Errorf("failed to read HEAD: %w", err)
 	}
@@ -130,7 +358,7 @@ func (s *commitService) UpdateLocalHead(env, commitID string) error {
 
 	head.LocalHead = commitID
 
-	if err := io.WriteJSONToFile(s.getHeadPath(env), head); err != nil {
+	if err := io.WriteJSON(s.store, s.getHeadPath(env), head); err != nil {
 		return fmt.Errorf("failed to update local HEAD: %w", err)
 	}
 
@@ -146,134 +374,70 @@ func (s *commitService) UpdateRemoteHead(env, commitID string) error {
 
 	head.RemoteHead = commitID
 
-	if err := io.WriteJSONToFile(s.getHeadPath(env), head); err != nil {
+	if err := io.WriteJSON(s.store, s.getHeadPath(env), head); err != nil {
 		return fmt.Errorf("failed to update remote HEAD: %w", err)
 	}
 
 	return nil
 }
 
-// ComputeState computes the final state of secrets up to a specific commit
+// ForgetRemote unlinks an environment from the remote: its commits count as
+// unpushed again and fetched commits and the record of pushed tags are dropped
+func (s *commitService) ForgetRemote(env string) error {
+	if err := s.UpdateRemoteHead(env, ""); err != nil {
+		return err
+	}
+	if err := s.store.Remove(envPath(env, RemoteTagsFileName)); err != nil {
+		return fmt.Errorf("failed to remove remote tags: %w", err)
+	}
+	return s.ClearFetched(env)
+}
+
+// ComputeState computes the final state of secrets up to a specific commit,
+// replaying commits from the nearest state snapshot
 func (s *commitService) ComputeState(env, upToCommitID string) (map[string]Secret, error) {
-	commits, err := s.loadCommits(env)
+	reader, err := s.commitLog(env).open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load commits: %w", err)
 	}
 
-	// Build commit chain up to the specified commit
-	commitChain, err := s.buildCommitChain(commits, upToCommitID)
+	stateMap, err := reader.state(upToCommitID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build commit chain: %w", err)
 	}
-
-	// Apply changes in chronological order
-	stateMap := make(map[string]Secret)
-	for _, commit := range commitChain {
-		for _, change := range commit.Changes {
-			switch change.Type {
-			case ChangeTypeAdd, ChangeTypeModify:
-				stateMap[change.Key] = Secret{
-					Key:      change.Key,
-					Value:    change.Value,
-					Nonce:    change.Nonce,
-					NoSecret: change.NoSecret,
-				}
-			case ChangeTypeRemove:
-				delete(stateMap, change.Key)
-			}
-		}
-	}
-
 	return stateMap, nil
 }
 
-// GetCommitsSinceRemoteHead returns all commits since the remote HEAD
-func (s *commitService) GetCommitsSinceRemoteHead(env string) ([]Commit, error) {
-	head, err := s.GetHead(env)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
-	}
-
-	// If no remote HEAD, return all commits
-	if head.RemoteHead == "" {
-		return s.ListCommits(env)
-	}
-
-	commits, err := s.loadCommits(env)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load commits: %w", err)
-	}
-
-	// Find commits since remote HEAD
-	var newCommits []Commit
-	remoteHeadFound := false
-
-	// Sort commits by timestamp (oldest first for this operation)
-	sort.Slice(commits, func(i, j int) bool {
-		return commits[i].Timestamp.Before(commits[j].Timestamp)
-	})
-
-	for _, commit := range commits {
-		if commit.ID == head.RemoteHead {
-			remoteHeadFound = true
-			continue
-		}
-		if remoteHeadFound {
-			newCommits = append(newCommits, commit)
+// ApplyChangesToState applies changes to a state map in place
+func ApplyChangesToState(state map[string]Secret, changes []Change) {
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeTypeAdd, ChangeTypeModify:
+			state[change.Key] = Secret{
+				Key:      change.Key,
+				Value:    change.Value,
+				Nonce:    change.Nonce,
+				NoSecret: change.NoSecret,
+			}
+		case ChangeTypeRemove:
+			delete(state, change.Key)
 		}
 	}
-
-	// If remote HEAD not found, return all commits
-	if !remoteHeadFound {
-		return commits, nil
-	}
-
-	return newCommits, nil
 }
 
-// loadCommits loads commits from disk
+// loadCommits loads every commit of an environment in the order they were stored
 func (s *commitService) loadCommits(env string) ([]Commit, error) {
-	path := s.getCommitsPath(env)
-	commits, err := io.ReadJSONFile[[]Commit](path)
+	commits, err := s.commitLog(env).all()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read commits file: %w", err)
 	}
 	return commits, nil
 }
 
-// saveCommits saves commits to disk
+// saveCommits replaces the stored commits of an environment
 func (s *commitService) saveCommits(env string, commits []Commit) error {
-	if err := io.WriteJSONToFile(s.getCommitsPath(env), commits); err != nil {
+	if err := s.commitLog(env).replace(commits); err != nil {
 		return fmt.Errorf("failed to write commits file: %w", err)
 	}
 	return nil
 }
-
-// buildCommitChain builds a chronological chain of commits up to a specific commit
-func (s *commitService) buildCommitChain(allCommits []Commit, upToCommitID string) ([]Commit, error) {
-	if upToCommitID == "" {
-		return []Commit{}, nil
-	}
-
-	// Create a map for fast lookup
-	commitMap := make(map[string]Commit)
-	for _, commit := range allCommits {
-		commitMap[commit.ID] = commit
-	}
-
-	// Build chain by following parent links backwards
-	var chain []Commit
-	currentID := upToCommitID
-
-	for currentID != "" {
-		commit, exists := commitMap[currentID]
-		if !exists {
-			return nil, fmt.Errorf("commit %s not found", currentID)
-		}
-
-		chain = append([]Commit{commit}, chain...) // Prepend to maintain chronological order
-		currentID = commit.ParentID
-	}
-
-	return chain, nil
-}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCommitService creates a commit service backed by a temporary environment directory
+func newTestCommitService(t *testing.T, env string) *commitService {
+	t.Helper()
+	workingDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workingDir, ".jebi", EnvDirPath, env), 0700))
+	return NewCommitService(workingDir)
+}
+
+func TestResolveCommit(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "abc123456789", Message: "first"},
+		{ID: "abd987654321", Message: "second", ParentID: "abc123456789"},
+	}))
+
+	commit, err := svc.ResolveCommit("dev", "abc123456789")
+	require.NoError(t, err, "Full IDs should resolve")
+	assert.Equal(t, "first", commit.Message)
+
+	commit, err = svc.ResolveCommit("dev", "abd")
+	require.NoError(t, err, "Unique prefixes should resolve")
+	assert.Equal(t, "second", commit.Message)
+
+	_, err = svc.ResolveCommit("dev", "ab")
+	assert.ErrorIs(t, err, ErrAmbiguousCommit)
+
+	_, err = svc.ResolveCommit("dev", "fff")
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+}
+
+func TestComputeCommitID(t *testing.T) {
+	changes := []Change{
+		{Type: ChangeTypeAdd, Key: "B", Value: "2", Nonce: "n2"},
+		{Type: ChangeTypeAdd, Key: "A", Value: "1", Nonce: "n1"},
+	}
+	reordered := []Change{changes[1], changes[0]}
+
+	id := ComputeCommitID("parent", changes)
+	assert.Len(t, id, 12)
+	assert.Equal(t, id, ComputeCommitID("parent", reordered), "Change order should not affect the ID")
+	assert.NotEqual(t, id, ComputeCommitID("other", changes), "Different parents should produce different IDs")
+	assert.NotEqual(t, id, ComputeCommitID("parent", changes[:1]), "Different changes should produce different IDs")
+}
+
+func TestCheckHistory(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	changes := []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}
+
+	first, err := svc.AddCommit("", "dev", "first", "tester", changes, time.Now())
+	require.NoError(t, err)
+	_, err = svc.AddCommit("", "dev", "second", "tester", changes, time.Now())
+	require.NoError(t, err)
+
+	issues, err := svc.CheckHistory("dev")
+	require.NoError(t, err)
+	assert.Empty(t, issues, "Freshly created history should be clean")
+
+	// Tamper with the first commit and point the remote HEAD nowhere
+	commits, err := svc.loadCommits("dev")
+	require.NoError(t, err)
+	commits[0].Changes[0].Value = "tampered"
+	commits = append(commits, commits[0])
+	require.NoError(t, svc.saveCommits("dev", commits))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "missing"))
+
+	issues, err = svc.CheckHistory("dev")
+	require.NoError(t, err)
+
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.Message)
+	}
+	assert.Contains(t, messages, "duplicate commit ID "+first.ID)
+	assert.Contains(t, messages, "commit "+first.ID+" does not match its content (legacy ID or modified history)")
+	assert.Contains(t, messages, "remote HEAD points to missing commit missing")
+}
+
+func TestRecommitReusesExistingCommit(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	changes := []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}
+
+	first, err := svc.AddCommit("", "dev", "first", "tester", changes, time.Now())
+	require.NoError(t, err)
+
+	// Undo the commit and record the same changes again
+	require.NoError(t, svc.UpdateLocalHead("dev", ""))
+	again, err := svc.AddCommit("", "dev", "again", "tester", changes, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, "first", again.Message, "The stored commit should be reused")
+
+	commits, err := svc.loadCommits("dev")
+	require.NoError(t, err)
+	assert.Len(t, commits, 1, "An existing ID should not be appended again")
+
+	_, err = svc.ImportCommit("dev", *first)
+	require.NoError(t, err)
+	issues, err := svc.CheckHistory("dev")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
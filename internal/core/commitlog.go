@@ -0,0 +1,368 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+// snapshotInterval is the distance along the first-parent chain between commits
+// whose state is snapshotted, bounding how many commits ComputeState replays
+var snapshotInterval = 100
+
+// commitIndexEntry locates one commit in the commit log
+type commitIndexEntry struct {
+	ID     string `json:"id"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"` // Including the trailing newline
+	Depth  int    `json:"depth"`  // Position on the first-parent chain, the root being 1
+}
+
+// stateSnapshot is the state of an environment's secrets at a commit
+type stateSnapshot struct {
+	CommitID string            `json:"commitId"`
+	State    map[string]Secret `json:"state"`
+}
+
+// commitLog is the commit history of one environment: an append-only log with
+// one JSON commit per line, an index of where each commit sits in the log and
+// state snapshots taken every snapshotInterval commits
+type commitLog struct {
+	store io.Store
+	dir   string
+}
+
+func (l commitLog) logPath() string {
+	return path.Join(l.dir, CommitLogFileName)
+}
+
+func (l commitLog) indexPath() string {
+	return path.Join(l.dir, CommitIndexFileName)
+}
+
+func (l commitLog) snapshotPath(commitID string) string {
+	return path.Join(l.dir, SnapshotDirPath, commitID)
+}
+
+// all reads every commit in log order
+func (l commitLog) all() ([]Commit, error) {
+	data, err := l.store.ReadFile(l.logPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit log: %w", err)
+	}
+
+	var commits []Commit
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var commit Commit
+		if err := json.Unmarshal(line, &commit); err != nil {
+			return nil, fmt.Errorf("failed to parse commit log line %d: %w", i+1, err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// index returns the index entries in log order. An index that does not cover
+// the whole log, e.g. one from before an interrupted write, is rebuilt.
+func (l commitLog) index() ([]commitIndexEntry, error) {
+	info, err := l.store.Stat(l.logPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit log: %w", err)
+	}
+
+	entries, err := l.readIndex()
+	if err == nil && indexEnd(entries) == info.Size {
+		return entries, nil
+	}
+
+	commits, err := l.all()
+	if err != nil {
+		return nil, err
+	}
+	_, entries, err = encodeCommits(commits, 0, make(map[string]int))
+	if err != nil {
+		return nil, err
+	}
+	if err := l.store.WriteFile(l.indexPath(), encodeIndex(entries)); err != nil {
+		return nil, fmt.Errorf("failed to rebuild commit index: %w", err)
+	}
+	return entries, nil
+}
+
+// append adds commits to the end of the log and indexes them. Commits whose
+// ID is already in the log are skipped, so an ID is never stored twice.
+func (l commitLog) append(commits ...Commit) error {
+	entries, err := l.index()
+	if err != nil {
+		return err
+	}
+	depths := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		depths[entry.ID] = entry.Depth
+	}
+
+	fresh := make([]Commit, 0, len(commits))
+	seen := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		if _, ok := depths[commit.ID]; ok || seen[commit.ID] {
+			continue
+		}
+		seen[commit.ID] = true
+		fresh = append(fresh, commit)
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	data, added, err := encodeCommits(fresh, indexEnd(entries), depths)
+	if err != nil {
+		return err
+	}
+	if err := l.store.AppendFile(l.logPath(), data); err != nil {
+		return fmt.Errorf("failed to append to commit log: %w", err)
+	}
+	if err := l.store.AppendFile(l.indexPath(), encodeIndex(added)); err != nil {
+		return fmt.Errorf("failed to append to commit index: %w", err)
+	}
+	return l.snapshot(added)
+}
+
+// replace rewrites the whole log and its index
+func (l commitLog) replace(commits []Commit) error {
+	data, entries, err := encodeCommits(commits, 0, make(map[string]int))
+	if err != nil {
+		return err
+	}
+	if err := l.store.WriteFile(l.logPath(), data); err != nil {
+		return fmt.Errorf("failed to write commit log: %w", err)
+	}
+	if err := l.store.WriteFile(l.indexPath(), encodeIndex(entries)); err != nil {
+		return fmt.Errorf("failed to write commit index: %w", err)
+	}
+	return l.snapshot(entries)
+}
+
+// snapshot saves the state at every indexed commit due for a snapshot that
+// does not have one yet
+func (l commitLog) snapshot(entries []commitIndexEntry) error {
+	for _, entry := range entries {
+		if entry.Depth%snapshotInterval != 0 {
+			continue
+		}
+		if _, err := l.store.Stat(l.snapshotPath(entry.ID)); err == nil {
+			continue
+		}
+
+		reader, err := l.open()
+		if err != nil {
+			return err
+		}
+		state, err := reader.state(entry.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := l.store.MkdirAll(path.Join(l.dir, SnapshotDirPath)); err != nil {
+			return fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+		if err := io.WriteJSON(l.store, l.snapshotPath(entry.ID), stateSnapshot{CommitID: entry.ID, State: state}); err != nil {
+			return fmt.Errorf("failed to write state snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// commitReader reads single commits from the log through its index
+type commitReader struct {
+	commitLog
+	entries []commitIndexEntry
+	byID    map[string]commitIndexEntry
+}
+
+// open loads the index for random access to the log. An environment without
+// commits yields a reader that finds nothing.
+func (l commitLog) open() (*commitReader, error) {
+	entries, err := l.index()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &commitReader{
+		commitLog: l,
+		entries:   entries,
+		byID:      make(map[string]commitIndexEntry, len(entries)),
+	}
+	for _, entry := range entries {
+		reader.byID[entry.ID] = entry
+	}
+	return reader, nil
+}
+
+// get reads the commit with the given ID; ok is false when there is none
+func (r *commitReader) get(id string) (commit Commit, ok bool, err error) {
+	entry, ok := r.byID[id]
+	if !ok {
+		return commit, false, nil
+	}
+
+	line := make([]byte, entry.Length)
+	if err := r.store.ReadAt(r.logPath(), line, entry.Offset); err != nil {
+		return commit, false, fmt.Errorf("failed to read commit %s: %w", id, err)
+	}
+	if err := json.Unmarshal(line, &commit); err != nil {
+		return commit, false, fmt.Errorf("failed to parse commit %s: %w", id, err)
+	}
+	if commit.ID != id {
+		return commit, false, fmt.Errorf("commit index is out of date: expected %s at offset %d, found %s", id, entry.Offset, commit.ID)
+	}
+	return commit, true, nil
+}
+
+// chain follows first parents back from upTo and returns the commits oldest first
+func (r *commitReader) chain(upTo string) ([]Commit, error) {
+	var chain []Commit
+	visited := make(map[string]bool)
+	for id := upTo; id != ""; {
+		if visited[id] {
+			return nil, fmt.Errorf("commit %s appears twice in its own ancestry", id)
+		}
+		visited[id] = true
+
+		commit, ok, err := r.get(id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("commit %s not found", id)
+		}
+		chain = append(chain, commit)
+		id = commit.ParentID
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// state computes the state at upTo, starting from the nearest snapshot on its
+// first-parent chain
+func (r *commitReader) state(upTo string) (map[string]Secret, error) {
+	var replay []Commit
+	state := make(map[string]Secret)
+	visited := make(map[string]bool)
+	for id := upTo; id != ""; {
+		if visited[id] {
+			return nil, fmt.Errorf("commit %s appears twice in its own ancestry", id)
+		}
+		visited[id] = true
+
+		snapshot, err := io.ReadJSON[stateSnapshot](r.store, r.snapshotPath(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read state snapshot: %w", err)
+		}
+		if snapshot.CommitID == id {
+			if snapshot.State != nil {
+				state = snapshot.State
+			}
+			break
+		}
+
+		commit, ok, err := r.get(id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("commit %s not found", id)
+		}
+		replay = append(replay, commit)
+		id = commit.ParentID
+	}
+
+	for i := len(replay) - 1; i >= 0; i-- {
+		ApplyChangesToState(state, replay[i].Changes)
+	}
+	return state, nil
+}
+
+// encodeCommits serializes commits as log lines starting at offset and indexes
+// them. depths holds the depth of known commits and is extended in place.
+func encodeCommits(commits []Commit, offset int64, depths map[string]int) ([]byte, []commitIndexEntry, error) {
+	var data []byte
+	entries := make([]commitIndexEntry, 0, len(commits))
+	for _, commit := range commits {
+		line, err := json.Marshal(commit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode commit %s: %w", commit.ID, err)
+		}
+		line = append(line, '\n')
+
+		depth := depths[commit.ParentID] + 1
+		depths[commit.ID] = depth
+		entries = append(entries, commitIndexEntry{
+			ID:     commit.ID,
+			Offset: offset,
+			Length: int64(len(line)),
+			Depth:  depth,
+		})
+		data = append(data, line...)
+		offset += int64(len(line))
+	}
+	return data, entries, nil
+}
+
+func encodeIndex(entries []commitIndexEntry) []byte {
+	var data []byte
+	for _, entry := range entries {
+		// Marshalling a plain struct cannot fail
+		line, _ := json.Marshal(entry)
+		data = append(append(data, line...), '\n')
+	}
+	return data
+}
+
+func (l commitLog) readIndex() ([]commitIndexEntry, error) {
+	data, err := l.store.ReadFile(l.indexPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit index: %w", err)
+	}
+
+	var entries []commitIndexEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry commitIndexEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse commit index: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// indexEnd returns the log size the index covers
+func indexEnd(entries []commitIndexEntry) int64 {
+	if len(entries) == 0 {
+		return 0
+	}
+	last := entries[len(entries)-1]
+	return last.Offset + last.Length
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStateStartsFromSnapshot(t *testing.T) {
+	defer func(interval int) { snapshotInterval = interval }(snapshotInterval)
+	snapshotInterval = 3
+
+	svc := newTestCommitService(t, "dev")
+	var ids []string
+	for i := 1; i <= 7; i++ {
+		value := fmt.Sprintf("v%d", i)
+		commit, err := svc.AddCommit("", "dev", value, "me", []Change{{Type: ChangeTypeModify, Key: "A", Value: value}}, time.Now())
+		require.NoError(t, err)
+		ids = append(ids, commit.ID)
+	}
+
+	log := svc.commitLog("dev")
+	for i, id := range ids {
+		_, err := svc.store.Stat(log.snapshotPath(id))
+		assert.Equal(t, (i+1)%3 == 0, err == nil, "Commit %d should be snapshotted only every third commit", i+1)
+	}
+
+	state, err := svc.ComputeState("dev", ids[6])
+	require.NoError(t, err)
+	assert.Equal(t, "v7", state["A"].Value)
+
+	// A snapshot stands in for every commit before it
+	require.NoError(t, io.WriteJSON(svc.store, log.snapshotPath(ids[5]), stateSnapshot{
+		CommitID: ids[5],
+		State:    map[string]Secret{"B": {Key: "B", Value: "from-snapshot"}},
+	}))
+	state, err = svc.ComputeState("dev", ids[6])
+	require.NoError(t, err)
+	assert.Equal(t, "from-snapshot", state["B"].Value)
+	assert.Equal(t, "v7", state["A"].Value)
+}
+
+func TestCommitIndexIsRebuilt(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	first, err := svc.AddCommit("", "dev", "first", "me", []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}, time.Now())
+	require.NoError(t, err)
+
+	log := svc.commitLog("dev")
+	require.NoError(t, svc.store.Remove(log.indexPath()))
+	second, err := svc.AddCommit("", "dev", "second", "me", []Change{{Type: ChangeTypeAdd, Key: "B", Value: "2"}}, time.Now())
+	require.NoError(t, err)
+
+	entries, err := log.index()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []int{1, 2}, []int{entries[0].Depth, entries[1].Depth})
+
+	commit, err := svc.GetCommit("dev", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commit.Message)
+	commit, err = svc.ResolveCommit("dev", second.ID[:6])
+	require.NoError(t, err)
+	assert.Equal(t, "second", commit.Message)
+}
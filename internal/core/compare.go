@@ -0,0 +1,89 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// KeyStatus summarizes how a key compares across environments
+type KeyStatus string
+
+const (
+	KeyIdentical KeyStatus = "identical" // Present everywhere with the same value
+	KeyDifferent KeyStatus = "different" // Present everywhere with differing values
+	KeyMissing   KeyStatus = "missing"   // Absent from at least one environment
+)
+
+// KeyComparison holds the value fingerprint of a key in every environment that has it
+type KeyComparison struct {
+	Key          string            `json:"key"`
+	Status       KeyStatus         `json:"status"`
+	Fingerprints map[string]string `json:"fingerprints"`        // Environment name -> value fingerprint
+	MissingIn    []string          `json:"missingIn,omitempty"` // Environments without the key
+}
+
+// EnvComparison is the key-by-environment matrix produced by CompareEnvironments
+type EnvComparison struct {
+	Environments []string        `json:"environments"`
+	Keys         []KeyComparison `json:"keys"`
+}
+
+// fingerprintContext separates value fingerprints from any other use of the project key
+const fingerprintContext = "jebi value fingerprint v1"
+
+// DeriveFingerprintKey derives the HMAC key used for value fingerprints from the project key
+func DeriveFingerprintKey(projectKey []byte) []byte {
+	mac := hmac.New(sha256.New, projectKey)
+	mac.Write([]byte(fingerprintContext))
+	return mac.Sum(nil)
+}
+
+// ValueFingerprint returns a keyed hash of a plaintext value. Equal values have equal
+// fingerprints, but the value cannot be recovered or brute-forced without the key.
+func ValueFingerprint(fingerprintKey []byte, value string) string {
+	mac := hmac.New(sha256.New, fingerprintKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// CompareEnvironments builds the comparison matrix from the plaintext values of
+// each environment (environment -> key -> value). Keys are sorted by name.
+func CompareEnvironments(envs []string, values map[string]map[string]string, fingerprintKey []byte) EnvComparison {
+	keySet := make(map[string]bool)
+	for _, env := range envs {
+		for key := range values[env] {
+			keySet[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	comparison := EnvComparison{Environments: envs, Keys: make([]KeyComparison, 0, len(keys))}
+	for _, key := range keys {
+		row := KeyComparison{Key: key, Status: KeyIdentical, Fingerprints: make(map[string]string)}
+		first := ""
+		for _, env := range envs {
+			value, exists := values[env][key]
+			if !exists {
+				row.MissingIn = append(row.MissingIn, env)
+				row.Status = KeyMissing
+				continue
+			}
+			fingerprint := ValueFingerprint(fingerprintKey, value)
+			row.Fingerprints[env] = fingerprint
+			if first == "" {
+				first = fingerprint
+			} else if fingerprint != first && row.Status == KeyIdentical {
+				row.Status = KeyDifferent
+			}
+		}
+		comparison.Keys = append(comparison.Keys, row)
+	}
+	return comparison
+}
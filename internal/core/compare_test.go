@@ -0,0 +1,39 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareEnvironments(t *testing.T) {
+	key := DeriveFingerprintKey([]byte("0123456789abcdef0123456789abcdef"))
+	values := map[string]map[string]string{
+		"dev":     {"SHARED": "same", "DB_URL": "dev-db", "DEBUG": "1"},
+		"staging": {"SHARED": "same", "DB_URL": "staging-db", "DEBUG": "1"},
+		"prod":    {"SHARED": "same", "DB_URL": "prod-db"},
+	}
+
+	comparison := CompareEnvironments([]string{"dev", "staging", "prod"}, values, key)
+	require.Len(t, comparison.Keys, 3)
+
+	byKey := make(map[string]KeyComparison)
+	for _, row := range comparison.Keys {
+		byKey[row.Key] = row
+	}
+
+	assert.Equal(t, KeyIdentical, byKey["SHARED"].Status)
+	assert.Equal(t, KeyDifferent, byKey["DB_URL"].Status)
+	assert.Equal(t, KeyMissing, byKey["DEBUG"].Status)
+	assert.Equal(t, []string{"prod"}, byKey["DEBUG"].MissingIn)
+	assert.Equal(t, byKey["DEBUG"].Fingerprints["dev"], byKey["DEBUG"].Fingerprints["staging"])
+
+	for _, fingerprint := range byKey["SHARED"].Fingerprints {
+		assert.NotContains(t, fingerprint, "same", "Fingerprints must not reveal values")
+	}
+
+	otherKey := DeriveFingerprintKey([]byte("another project key, 32 bytes!!!"))
+	assert.NotEqual(t, ValueFingerprint(key, "same"), ValueFingerprint(otherKey, "same"),
+		"Fingerprints should depend on the project key")
+}
@@ -10,12 +10,26 @@ const (
 	AppVersion     = "0.1.0"
 	KeyLengthBytes = 32
 
-	KeyFilePath       = "keys/enc.key"
-	EnvDirPath        = "envs"
-	SecretFileName    = "sec"
-	ProjectConfigFile = "pro"
-	CommitFileName    = "commits"
-	CurrentFileName   = "current"
+	KeyFilePath            = "keys/enc.key"
+	EnvDirPath             = "envs"
+	SecretFileName         = "sec"
+	ProjectConfigFile      = "pro"
+	CommitFileName         = "commits" // Commit array written before layout version 2
+	CommitLogFileName      = "commits.log"
+	CommitIndexFileName    = "commits.idx"
+	SnapshotDirPath        = "snapshots"
+	CurrentFileName        = "current"
+	PendingChangesFileName = "changes"
+	StashFileName          = "stash"
+	TagsFileName           = "tags"
+	RemoteTagsFileName     = "remote_tags" // Tags as last sent to or received from the remote
+	FetchFileName          = "fetched"
+	RebaseFileName         = "rebase"
+	ReflogFileName         = "reflog"
+	TrashDirPath           = "trash"
+	LockFileName           = "lock"
+	JournalFileName        = "journal"
+	LayoutVersionFileName  = "version"
 
 	DefaultEnvironment = "dev"
 	DefaultProjectName = "my-jebi-project"
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SecretDiff describes how a single key differs between two secret states
+type SecretDiff struct {
+	Type ChangeType
+	Key  string
+	Old  *Secret // nil for added keys
+	New  *Secret // nil for removed keys
+}
+
+// DiffStates compares two secret states and returns the differences sorted by key
+func DiffStates(from, to map[string]Secret) []SecretDiff {
+	var diffs []SecretDiff
+
+	for key, oldSecret := range from {
+		newSecret, exists := to[key]
+		if !exists {
+			old := oldSecret
+			diffs = append(diffs, SecretDiff{Type: ChangeTypeRemove, Key: key, Old: &old})
+			continue
+		}
+		if !sameSecretValue(oldSecret, newSecret) {
+			old, updated := oldSecret, newSecret
+			diffs = append(diffs, SecretDiff{Type: ChangeTypeModify, Key: key, Old: &old, New: &updated})
+		}
+	}
+
+	for key, newSecret := range to {
+		if _, exists := from[key]; !exists {
+			added := newSecret
+			diffs = append(diffs, SecretDiff{Type: ChangeTypeAdd, Key: key, New: &added})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+
+	return diffs
+}
+
+// DiffValues compares two secret states like DiffStates, but by plaintext: a key
+// set again to the same value is encrypted with a new nonce and is not reported
+// as modified. decrypt returns the plaintext of a stored secret.
+func DiffValues(from, to map[string]Secret, decrypt func(Secret) (string, error)) ([]SecretDiff, error) {
+	var diffs []SecretDiff
+	for _, diff := range DiffStates(from, to) {
+		if diff.Type == ChangeTypeModify && diff.Old.NoSecret == diff.New.NoSecret {
+			oldValue, err := decrypt(*diff.Old)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt '%s': %w", diff.Key, err)
+			}
+			newValue, err := decrypt(*diff.New)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt '%s': %w", diff.Key, err)
+			}
+			if oldValue == newValue {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// SecretsToState converts a list of secrets into a state map keyed by secret key
+func SecretsToState(secrets []Secret) map[string]Secret {
+	state := make(map[string]Secret, len(secrets))
+	for _, secret := range secrets {
+		state[secret.Key] = Secret{
+			Key:      secret.Key,
+			Value:    secret.Value,
+			Nonce:    secret.Nonce,
+			NoSecret: secret.NoSecret,
+		}
+	}
+	return state
+}
+
+// sameSecretValue reports whether two secrets hold the same stored value
+func sameSecretValue(a, b Secret) bool {
+	return a.Value == b.Value && a.Nonce == b.Nonce && a.NoSecret == b.NoSecret
+}
+
+// ChangesBetween returns the changes that turn the from state into the to state
+func ChangesBetween(from, to map[string]Secret) []Change {
+	diffs := DiffStates(from, to)
+	changes := make([]Change, 0, len(diffs))
+	for _, diff := range diffs {
+		change := Change{Type: diff.Type, Key: diff.Key}
+		if diff.New != nil {
+			change.Value = diff.New.Value
+			change.Nonce = diff.New.Nonce
+			change.NoSecret = diff.New.NoSecret
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStates(t *testing.T) {
+	from := map[string]Secret{
+		"API_KEY":      {Key: "API_KEY", Value: "old", Nonce: "n1"},
+		"DATABASE_URL": {Key: "DATABASE_URL", Value: "db", Nonce: "n2"},
+		"LOG_LEVEL":    {Key: "LOG_LEVEL", Value: "info", NoSecret: true},
+	}
+	to := map[string]Secret{
+		"API_KEY":   {Key: "API_KEY", Value: "new", Nonce: "n3"},
+		"LOG_LEVEL": {Key: "LOG_LEVEL", Value: "info", NoSecret: true},
+		"REDIS_URL": {Key: "REDIS_URL", Value: "redis", Nonce: "n4"},
+	}
+
+	diffs := DiffStates(from, to)
+	require.Len(t, diffs, 3, "Unchanged keys should not be reported")
+
+	assert.Equal(t, "API_KEY", diffs[0].Key)
+	assert.Equal(t, ChangeTypeModify, diffs[0].Type)
+	assert.Equal(t, "old", diffs[0].Old.Value)
+	assert.Equal(t, "new", diffs[0].New.Value)
+
+	assert.Equal(t, "DATABASE_URL", diffs[1].Key)
+	assert.Equal(t, ChangeTypeRemove, diffs[1].Type)
+	assert.Nil(t, diffs[1].New, "Removed keys should have no new value")
+
+	assert.Equal(t, "REDIS_URL", diffs[2].Key)
+	assert.Equal(t, ChangeTypeAdd, diffs[2].Type)
+	assert.Nil(t, diffs[2].Old, "Added keys should have no old value")
+}
+
+func TestDiffStatesIdentical(t *testing.T) {
+	state := map[string]Secret{
+		"API_KEY": {Key: "API_KEY", Value: "value", Nonce: "nonce"},
+	}
+	assert.Empty(t, DiffStates(state, state), "Identical states should produce no differences")
+	assert.Empty(t, DiffStates(nil, nil), "Empty states should produce no differences")
+}
+
+func TestDiffValuesIgnoresReencryptedValues(t *testing.T) {
+	from := map[string]Secret{
+		"API_KEY":   {Key: "API_KEY", Value: "enc(same)", Nonce: "n1"},
+		"LOG_LEVEL": {Key: "LOG_LEVEL", Value: "enc(info)", Nonce: "n2"},
+	}
+	to := map[string]Secret{
+		"API_KEY":   {Key: "API_KEY", Value: "enc(same)#2", Nonce: "n3"}, // Set again to the same value
+		"LOG_LEVEL": {Key: "LOG_LEVEL", Value: "enc(debug)", Nonce: "n4"},
+	}
+	plaintexts := map[string]string{"n1": "same", "n2": "info", "n3": "same", "n4": "debug"}
+	decrypt := func(secret Secret) (string, error) {
+		return plaintexts[secret.Nonce], nil
+	}
+
+	require.Len(t, DiffStates(from, to), 2)
+	diffs, err := DiffValues(from, to, decrypt)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1, "A key set again to the same value should not be reported")
+	assert.Equal(t, "LOG_LEVEL", diffs[0].Key)
+	assert.Equal(t, ChangeTypeModify, diffs[0].Type)
+}
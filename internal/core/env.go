@@ -1,9 +1,12 @@
 package core
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/jawahars16/jebi/internal/io"
 )
@@ -13,79 +16,145 @@ var (
 )
 
 type envService struct {
-	workingDir string
+	store io.Store
 }
 
 func NewEnvService(workingDir string) *envService {
+	return NewEnvServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewEnvServiceWithStore creates an environment service backed by store
+func NewEnvServiceWithStore(store io.Store) *envService {
 	return &envService{
-		workingDir: workingDir,
+		store: store,
 	}
 }
 
-// currentEnvPath returns the path to the "current" file
+// currentEnvPath returns the store name of the "current" file
 func (e *envService) currentEnvPath() string {
-	return filepath.Join(e.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, CurrentFileName)
-}
-
-// envDir returns the directory for a given environment
-func (e *envService) envDir(env string) string {
-	return filepath.Join(e.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, env)
+	return path.Join(EnvDirPath, CurrentFileName)
 }
 
 // CurrentEnv reads the active environment from ".<AppName>/current"
 func (e *envService) CurrentEnv() (string, error) {
-	path := e.currentEnvPath()
-	currentEnv, err := io.ReadJSONFile[CurrentEnv](path)
+	currentEnv, err := e.readCurrentFile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", ErrCurrentEnvNotExist
-		}
 		return "", err
 	}
 	return currentEnv.Env, nil
 }
 
+// GetCurrentEnv returns the active environment together with its pending changes
 func (e *envService) GetCurrentEnv() (*CurrentEnv, error) {
-	path := e.currentEnvPath()
-	currentEnv, err := io.ReadJSONFile[CurrentEnv](path)
+	currentEnv, err := e.readCurrentFile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, ErrCurrentEnvNotExist
-		}
 		return nil, err
 	}
+
+	changes, err := loadPendingChanges(e.store, currentEnv.Env)
+	if err != nil {
+		return nil, err
+	}
+	currentEnv.Changes = changes
 	return &currentEnv, nil
 }
 
-// SetCurrentEnv sets the current active environment in ".<AppName>/current"
+// SetCurrentEnv sets the current active environment in ".<AppName>/current".
+// Pending changes stay with the environment they were made in.
 func (e *envService) SetCurrentEnv(env string) error {
-	path := e.currentEnvPath()
-	currentEnv := CurrentEnv{
-		Env:     env,
-		Changes: []Change{},
+	if _, err := e.readCurrentFile(); err != nil && err != ErrCurrentEnvNotExist {
+		return err
 	}
-	return io.WriteJSONToFile(path, currentEnv)
+	return io.WriteJSON(e.store, e.currentEnvPath(), CurrentEnv{Env: env})
+}
+
+// readCurrentFile reads the "current" file, moving pending changes that older
+// versions stored there into the environment's own pending changes file
+func (e *envService) readCurrentFile() (CurrentEnv, error) {
+	currentPath := e.currentEnvPath()
+	currentEnv, err := io.ReadJSON[CurrentEnv](e.store, currentPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return CurrentEnv{}, ErrCurrentEnvNotExist
+		}
+		return CurrentEnv{}, err
+	}
+
+	if len(currentEnv.Changes) > 0 && currentEnv.Env != "" {
+		existing, err := loadPendingChanges(e.store, currentEnv.Env)
+		if err != nil {
+			return CurrentEnv{}, err
+		}
+		if err := savePendingChanges(e.store, currentEnv.Env, normalizeChanges(append(existing, currentEnv.Changes...))); err != nil {
+			return CurrentEnv{}, err
+		}
+		currentEnv.Changes = nil
+		if err := io.WriteJSON(e.store, currentPath, currentEnv); err != nil {
+			return CurrentEnv{}, fmt.Errorf("failed to write current environment: %w", err)
+		}
+	}
+
+	return currentEnv, nil
 }
 
 // CreateEnv creates a new environment folder: ".<AppName>/<env>"
 func (e *envService) CreateEnv(env string) error {
-	envDir := e.envDir(env)
-	if err := os.MkdirAll(envDir, 0700); err != nil {
+	if err := e.store.MkdirAll(envPath(env)); err != nil {
 		return fmt.Errorf("failed to create environment '%s': %w", env, err)
 	}
 	return nil
 }
 
 func (e *envService) EnvExists(env string) (bool, error) {
-	envDir := e.envDir(env)
-	info, err := os.Stat(envDir)
+	info, err := e.store.Stat(envPath(env))
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return false, nil
 		}
 		return false, err
 	}
-	return info.IsDir(), nil
+	return info.IsDir, nil
+}
+
+// RenameEnv moves an environment directory and updates the current environment
+// if it pointed to the old name
+func (e *envService) RenameEnv(oldName, newName string) error {
+	if newName == "" || newName != filepath.Base(newName) || strings.HasPrefix(newName, ".") {
+		return fmt.Errorf("invalid environment name '%s'", newName)
+	}
+
+	exists, err := e.EnvExists(oldName)
+	if err != nil {
+		return fmt.Errorf("failed to check if environment exists: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("environment '%s' does not exist", oldName)
+	}
+
+	exists, err = e.EnvExists(newName)
+	if err != nil {
+		return fmt.Errorf("failed to check if environment exists: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("environment '%s' already exists", newName)
+	}
+
+	// Settle any legacy pending changes into the old directory before it moves
+	current, err := e.readCurrentFile()
+	if err != nil {
+		return err
+	}
+
+	if err := e.store.Rename(envPath(oldName), envPath(newName)); err != nil {
+		return fmt.Errorf("failed to rename environment '%s': %w", oldName, err)
+	}
+
+	if current.Env == oldName {
+		if err := e.SetCurrentEnv(newName); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (e *envService) RemoveEnv(env string) error {
@@ -98,15 +167,15 @@ func (e *envService) RemoveEnv(env string) error {
 		return fmt.Errorf("environment '%s' does not exist", env)
 	}
 
-	envDir := e.envDir(env)
-	if err := os.RemoveAll(envDir); err != nil {
+	// Keep the removed environment in the trash so `undo` can bring it back
+	if err := moveToTrash(e.store, env); err != nil {
 		return fmt.Errorf("failed to delete environment '%s': %w", env, err)
 	}
 
 	// if the deleted env was the current env, unset current env
-	currentEnv, err := io.ReadJSONFile[CurrentEnv](e.currentEnvPath())
+	currentEnv, err := io.ReadJSON[CurrentEnv](e.store, e.currentEnvPath())
 	if err == nil && currentEnv.Env == env {
-		if err := os.Remove(e.currentEnvPath()); err != nil {
+		if err := e.store.Remove(e.currentEnvPath()); err != nil {
 			return fmt.Errorf("failed to unset current environment: %w", err)
 		}
 	}
@@ -114,27 +183,26 @@ func (e *envService) RemoveEnv(env string) error {
 	return nil
 }
 
-func (e *envService) HasPendingChanges() (bool, error) {
-	path := e.currentEnvPath()
-	currentEnv, err := io.ReadJSONFile[CurrentEnv](path)
+// HasPendingChanges reports whether an environment has uncommitted changes
+func (e *envService) HasPendingChanges(env string) (bool, error) {
+	changes, err := loadPendingChanges(e.store, env)
 	if err != nil {
-		return false, fmt.Errorf("failed to read current environment: %w", err)
+		return false, err
 	}
-	return len(currentEnv.Changes) > 0, nil
+	return len(changes) > 0, nil
 }
 
 // ListEnvs lists all environment folders inside ".<AppName>"
 func (e *envService) ListEnvs() ([]string, error) {
-	dir := filepath.Join(e.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath)
-	entries, err := os.ReadDir(dir)
+	entries, err := e.store.ReadDir(EnvDirPath)
 	if err != nil {
 		return nil, err
 	}
 
 	envs := []string{}
 	for _, e := range entries {
-		if e.IsDir() {
-			envs = append(envs, e.Name())
+		if e.IsDir {
+			envs = append(envs, e.Name)
 		}
 	}
 	return envs, nil
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingChangesSurviveEnvSwitch(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	changeSvc := NewChangeRecordService(workingDir)
+
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	require.NoError(t, envSvc.CreateEnv("prod"))
+	require.NoError(t, envSvc.SetCurrentEnv("dev"))
+	require.NoError(t, changeSvc.AddChangeRecord("dev", string(ChangeTypeAdd), "API_KEY", "v", "n", false))
+
+	require.NoError(t, envSvc.SetCurrentEnv("prod"))
+	current, err := envSvc.GetCurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current.Env)
+	assert.Empty(t, current.Changes, "The new environment should start clean")
+
+	dirty, err := envSvc.HasPendingChanges("dev")
+	require.NoError(t, err)
+	assert.True(t, dirty, "Changes should stay staged in the environment they were made in")
+}
+
+func TestLegacyPendingChangesAreMigrated(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+
+	// Older versions kept pending changes in the "current" file
+	legacy := CurrentEnv{Env: "dev", Changes: []Change{{Type: ChangeTypeAdd, Key: "API_KEY", Value: "v"}}}
+	require.NoError(t, io.WriteJSON(envSvc.store, envSvc.currentEnvPath(), legacy))
+
+	current, err := envSvc.GetCurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, legacy.Changes, current.Changes)
+
+	stored, err := io.ReadJSON[CurrentEnv](envSvc.store, envSvc.currentEnvPath())
+	require.NoError(t, err)
+	assert.Empty(t, stored.Changes, "Changes should be moved out of the current file")
+}
+
+func TestRenameEnv(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	changeSvc := NewChangeRecordService(workingDir)
+
+	require.NoError(t, envSvc.CreateEnv("staging"))
+	require.NoError(t, envSvc.CreateEnv("prod"))
+	require.NoError(t, envSvc.SetCurrentEnv("staging"))
+	require.NoError(t, changeSvc.AddChangeRecord("staging", string(ChangeTypeAdd), "API_KEY", "v", "n", false))
+
+	assert.Error(t, envSvc.RenameEnv("staging", "prod"), "Renaming onto an existing environment should fail")
+	assert.Error(t, envSvc.RenameEnv("staging", "../outside"), "Names must not escape the environments directory")
+	assert.Error(t, envSvc.RenameEnv("missing", "other"))
+
+	require.NoError(t, envSvc.RenameEnv("staging", "stage"))
+
+	current, err := envSvc.GetCurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "stage", current.Env, "The current environment should follow the rename")
+	assert.Len(t, current.Changes, 1, "Pending changes should move with the environment")
+
+	exists, err := envSvc.EnvExists("staging")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
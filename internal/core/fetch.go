@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+var (
+	ErrDiverged = fmt.Errorf("local and remote histories have diverged")
+)
+
+// FetchedCommits are remote commits downloaded by fetch but not yet integrated
+// into the local history
+type FetchedCommits struct {
+	BaseCommit string    `json:"baseCommit,omitempty"` // Remote HEAD the commits build on
+	Head       string    `json:"head"`                 // Latest commit on the remote
+	Commits    []Commit  `json:"commits"`              // Commits after BaseCommit, oldest first
+	Tags       []Tag     `json:"tags,omitempty"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+}
+
+func (s *commitService) getFetchedPath(env string) string {
+	return envPath(env, FetchFileName)
+}
+
+// SaveFetched stores the result of a fetch, replacing any earlier one
+func (s *commitService) SaveFetched(env string, fetched FetchedCommits) error {
+	if err := io.WriteJSON(s.store, s.getFetchedPath(env), fetched); err != nil {
+		return fmt.Errorf("failed to write fetched commits: %w", err)
+	}
+	return nil
+}
+
+// GetFetched returns the last fetch result; Head is empty when nothing was fetched
+func (s *commitService) GetFetched(env string) (*FetchedCommits, error) {
+	fetched, err := io.ReadJSON[FetchedCommits](s.store, s.getFetchedPath(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fetched commits: %w", err)
+	}
+	return &fetched, nil
+}
+
+// ClearFetched discards the last fetch result
+func (s *commitService) ClearFetched(env string) error {
+	if err := s.store.Remove(s.getFetchedPath(env)); err != nil {
+		return fmt.Errorf("failed to remove fetched commits: %w", err)
+	}
+	return nil
+}
+
+// FastForward appends fetched commits on top of the local history and moves both
+// HEADs to the remote head. It fails with ErrDiverged when local commits exist
+// that the remote does not have.
+func (s *commitService) FastForward(env string, fetched FetchedCommits) error {
+	if err := s.FastForwardLocal(env, fetched); err != nil {
+		return err
+	}
+	return s.UpdateRemoteHead(env, fetched.Head)
+}
+
+// FastForwardLocal appends commits on top of the local history and moves only
+// the local HEAD, for commits that did not come from the remote (such as a
+// bundle's); they stay unpushed
+func (s *commitService) FastForwardLocal(env string, fetched FetchedCommits) error {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead != fetched.BaseCommit {
+		return fmt.Errorf("%w: local HEAD %s is not the fetched base %s", ErrDiverged, head.LocalHead, fetched.BaseCommit)
+	}
+
+	if err := s.ImportFetched(env, fetched); err != nil {
+		return err
+	}
+	return s.UpdateLocalHead(env, fetched.Head)
+}
+
+// ImportFetched stores fetched commits alongside the local history without
+// moving either HEAD, so they can be merged or rebased onto
+func (s *commitService) ImportFetched(env string, fetched FetchedCommits) error {
+	entries, err := s.commitLog(env).index()
+	if err != nil {
+		return fmt.Errorf("failed to load commits: %w", err)
+	}
+	known := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		known[entry.ID] = true
+	}
+
+	var commits []Commit
+
+	previous := fetched.BaseCommit
+	for _, commit := range fetched.Commits {
+		// Older servers do not return parent links; commits arrive in order
+		if commit.ParentID == "" {
+			commit.ParentID = previous
+		}
+		// Merged histories arrive parents first rather than as a single chain
+		if commit.ParentID != previous && !known[commit.ParentID] {
+			return fmt.Errorf("fetched commit %s does not follow %s", commit.ID, previous)
+		}
+		if commit.MergeParentID != "" && !known[commit.MergeParentID] {
+			return fmt.Errorf("fetched merge commit %s arrived before its parent %s", commit.ID, commit.MergeParentID)
+		}
+		if !known[commit.ID] {
+			commits = append(commits, commit)
+			known[commit.ID] = true
+		}
+		previous = commit.ID
+	}
+	if fetched.Head != previous {
+		return fmt.Errorf("fetched commits end at %s, but the remote head is %s", previous, fetched.Head)
+	}
+
+	if err := s.commitLog(env).append(commits...); err != nil {
+		return fmt.Errorf("failed to save fetched commits: %w", err)
+	}
+	return nil
+}
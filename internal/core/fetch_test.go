@@ -0,0 +1,105 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFastForward(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	base := Commit{ID: "base00000000", Changes: []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}}
+	require.NoError(t, svc.saveCommits("dev", []Commit{base}))
+	require.NoError(t, svc.UpdateLocalHead("dev", base.ID))
+	require.NoError(t, svc.UpdateRemoteHead("dev", base.ID))
+
+	fetched := FetchedCommits{
+		BaseCommit: base.ID,
+		Head:       "second000000",
+		Commits: []Commit{
+			{ID: "first0000000", ParentID: base.ID, Changes: []Change{{Type: ChangeTypeModify, Key: "A", Value: "2"}}},
+			{ID: "second000000", Changes: []Change{{Type: ChangeTypeAdd, Key: "B", Value: "3"}}}, // Legacy: no parent link
+		},
+	}
+
+	require.NoError(t, svc.FastForward("dev", fetched))
+
+	head, err := svc.GetHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, &Head{LocalHead: "second000000", RemoteHead: "second000000"}, head)
+
+	second, err := svc.GetCommit("dev", "second000000")
+	require.NoError(t, err)
+	assert.Equal(t, "first0000000", second.ParentID, "Missing parent links should be filled in order")
+
+	state, err := svc.ComputeState("dev", head.LocalHead)
+	require.NoError(t, err)
+	assert.Equal(t, "2", state["A"].Value)
+	assert.Equal(t, "3", state["B"].Value)
+}
+
+func TestFastForwardRefusesDivergedHistory(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "base00000000"},
+		{ID: "local0000000", ParentID: "base00000000"},
+	}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "local0000000"))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "base00000000"))
+
+	err := svc.FastForward("dev", FetchedCommits{
+		BaseCommit: "base00000000",
+		Head:       "remote000000",
+		Commits:    []Commit{{ID: "remote000000", ParentID: "base00000000"}},
+	})
+	assert.ErrorIs(t, err, ErrDiverged)
+}
+
+func TestFastForwardLocal(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{{ID: "base00000000"}, {ID: "local0000000", ParentID: "base00000000"}}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "local0000000"))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "base00000000"))
+
+	require.NoError(t, svc.FastForwardLocal("dev", FetchedCommits{
+		BaseCommit: "local0000000",
+		Head:       "bundled00000",
+		Commits:    []Commit{{ID: "bundled00000", ParentID: "local0000000"}},
+	}))
+	require.NoError(t, svc.ImportLocalTags("dev", []Tag{{Name: "v1.0", CommitID: "bundled00000"}}))
+
+	head, err := svc.GetHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, &Head{LocalHead: "bundled00000", RemoteHead: "base00000000"}, head, "The remote has not seen the commits")
+	unpushed, err := svc.GetCommitsSinceRemoteHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local0000000", "bundled00000"}, commitIDs(unpushed))
+	unpushedTags, err := svc.UnpushedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, tagNames(unpushedTags))
+
+	err = svc.FastForwardLocal("dev", FetchedCommits{BaseCommit: "base00000000", Head: "other0000000"})
+	assert.ErrorIs(t, err, ErrDiverged)
+}
+
+func TestForgetRemote(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{{ID: "base00000000"}}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "base00000000"))
+	require.NoError(t, svc.UpdateRemoteHead("dev", "base00000000"))
+	require.NoError(t, svc.ImportTags("dev", []Tag{{Name: "v1.0", CommitID: "base00000000"}}))
+	require.NoError(t, svc.SaveFetched("dev", FetchedCommits{BaseCommit: "base00000000", Head: "remote000000"}))
+
+	require.NoError(t, svc.ForgetRemote("dev"))
+
+	head, err := svc.GetHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, &Head{LocalHead: "base00000000"}, head)
+	fetched, err := svc.GetFetched("dev")
+	require.NoError(t, err)
+	assert.Empty(t, fetched.Head)
+	unpushed, err := svc.UnpushedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, tagNames(unpushed), "Tags should be pushed again under the new link")
+}
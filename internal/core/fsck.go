@@ -0,0 +1,117 @@
+package core
+
+import "fmt"
+
+// IssueSeverity classifies how serious an integrity problem is
+type IssueSeverity string
+
+const (
+	SeverityError   IssueSeverity = "error"
+	SeverityWarning IssueSeverity = "warning"
+)
+
+// IntegrityIssue describes a single problem found while checking an environment
+type IntegrityIssue struct {
+	Env      string        `json:"env"`
+	Severity IssueSeverity `json:"severity"`
+	Message  string        `json:"message"`
+}
+
+// CheckHistory verifies the commit history of an environment: duplicate IDs,
+// broken or cyclic parent chains, dangling HEAD and tag pointers, invalid signatures
+// and IDs that do not match their content
+func (s *commitService) CheckHistory(env string) ([]IntegrityIssue, error) {
+	commits, err := s.loadCommits(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commits: %w", err)
+	}
+
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	var issues []IntegrityIssue
+	report := func(severity IssueSeverity, format string, args ...any) {
+		issues = append(issues, IntegrityIssue{Env: env, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	commitMap := make(map[string]Commit, len(commits))
+	for _, commit := range commits {
+		if _, exists := commitMap[commit.ID]; exists {
+			report(SeverityError, "duplicate commit ID %s", commit.ID)
+			continue
+		}
+		commitMap[commit.ID] = commit
+	}
+
+	for _, commit := range commits {
+		if commit.ParentID != "" {
+			if _, exists := commitMap[commit.ParentID]; !exists {
+				report(SeverityError, "commit %s has missing parent %s", commit.ID, commit.ParentID)
+			}
+		}
+		if commit.MergeParentID != "" {
+			if _, exists := commitMap[commit.MergeParentID]; !exists {
+				report(SeverityError, "merge commit %s has missing parent %s", commit.ID, commit.MergeParentID)
+			}
+		}
+		if status, _ := VerifyCommitSignature(commit, nil); status == SignatureInvalid {
+			report(SeverityError, "commit %s has an invalid signature", commit.ID)
+		}
+		// Commits imported from older versions or the remote may use legacy IDs
+		if CommitContentID(commit) != commit.ID {
+			report(SeverityWarning, "commit %s does not match its content (legacy ID or modified history)", commit.ID)
+		}
+	}
+
+	for _, ref := range []struct{ name, id string }{
+		{"local HEAD", head.LocalHead},
+		{"remote HEAD", head.RemoteHead},
+	} {
+		if ref.id == "" {
+			continue
+		}
+		if _, exists := commitMap[ref.id]; !exists {
+			report(SeverityError, "%s points to missing commit %s", ref.name, ref.id)
+			continue
+		}
+		if cycleAt := findCycle(commitMap, ref.id); cycleAt != "" {
+			report(SeverityError, "parent chain from %s loops back to commit %s", ref.name, cycleAt)
+		}
+	}
+
+	if head.LocalHead == "" && len(commits) > 0 {
+		report(SeverityError, "local HEAD is empty but %d commit(s) exist", len(commits))
+	}
+
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if _, exists := commitMap[tag.CommitID]; !exists {
+			report(SeverityWarning, "tag %s points to missing commit %s", tag.Name, tag.CommitID)
+		}
+	}
+
+	return issues, nil
+}
+
+// findCycle follows parent links from a commit and returns the ID at which the
+// chain revisits itself, or an empty string when the chain terminates
+func findCycle(commitMap map[string]Commit, fromID string) string {
+	visited := make(map[string]bool)
+	for currentID := fromID; currentID != ""; {
+		if visited[currentID] {
+			return currentID
+		}
+		visited[currentID] = true
+		commit, exists := commitMap[currentID]
+		if !exists {
+			return ""
+		}
+		currentID = commit.ParentID
+	}
+	return ""
+}
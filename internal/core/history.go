@@ -0,0 +1,110 @@
+package core
+
+import "sort"
+
+// KeyVersion is one change to a key in the commit chain. Versions are
+// numbered from 1 in the order the changes were committed.
+type KeyVersion struct {
+	Version int
+	Commit  Commit
+	Change  Change
+}
+
+// GetCommitChain returns the commits from the root up to upToCommitID, oldest first
+func (s *commitService) GetCommitChain(env, upToCommitID string) ([]Commit, error) {
+	reader, err := s.commitLog(env).open()
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.chain(upToCommitID)
+}
+
+// KeyHistory lists every committed change to key in a chain, oldest first
+func KeyHistory(chain []Commit, key string) []KeyVersion {
+	var versions []KeyVersion
+	for _, commit := range chain {
+		for _, change := range commit.Changes {
+			if change.Key == key {
+				versions = append(versions, KeyVersion{
+					Version: len(versions) + 1,
+					Commit:  commit,
+					Change:  change,
+				})
+			}
+		}
+	}
+	return versions
+}
+
+// Blame returns the last change of every key that exists at the end of a chain,
+// sorted by key
+func Blame(chain []Commit) []KeyVersion {
+	latest := make(map[string]KeyVersion)
+	for _, commit := range chain {
+		for _, change := range commit.Changes {
+			version := KeyVersion{
+				Version: latest[change.Key].Version + 1,
+				Commit:  commit,
+				Change:  change,
+			}
+			latest[change.Key] = version
+		}
+	}
+
+	var result []KeyVersion
+	for _, version := range latest {
+		if version.Change.Type != ChangeTypeRemove {
+			result = append(result, version)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Change.Key < result[j].Change.Key
+	})
+	return result
+}
+
+// BlameHistory is Blame over the history up to commitID. A merge commit stores
+// its changes relative to the first parent, so keys it took unchanged from the
+// merged side are credited to the commit that changed them there.
+func (s *commitService) BlameHistory(env, commitID string) ([]KeyVersion, error) {
+	reader, err := s.commitLog(env).open()
+	if err != nil {
+		return nil, err
+	}
+	return blameHistory(reader, commitID, make(map[string][]KeyVersion))
+}
+
+func blameHistory(reader *commitReader, commitID string, blamed map[string][]KeyVersion) ([]KeyVersion, error) {
+	if entries, ok := blamed[commitID]; ok {
+		return entries, nil
+	}
+
+	chain, err := reader.chain(commitID)
+	if err != nil {
+		return nil, err
+	}
+	entries := Blame(chain)
+	for i, entry := range entries {
+		if entry.Commit.MergeParentID == "" {
+			continue
+		}
+		merged, err := blameHistory(reader, entry.Commit.MergeParentID, blamed)
+		if err != nil {
+			return nil, err
+		}
+		for _, origin := range merged {
+			if origin.Change.Key == entry.Change.Key && sameChangeValue(origin.Change, entry.Change) {
+				entries[i].Commit = origin.Commit
+				break
+			}
+		}
+	}
+
+	blamed[commitID] = entries
+	return entries, nil
+}
+
+func sameChangeValue(a, b Change) bool {
+	return a.Value == b.Value && a.Nonce == b.Nonce && a.NoSecret == b.NoSecret
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyHistoryAndBlame(t *testing.T) {
+	chain := []Commit{
+		{ID: "c1", Author: "alice", Changes: []Change{
+			{Type: ChangeTypeAdd, Key: "DB_URL", Value: "v1"},
+			{Type: ChangeTypeAdd, Key: "TOKEN", Value: "t1"},
+		}},
+		{ID: "c2", Author: "bob", Changes: []Change{
+			{Type: ChangeTypeModify, Key: "DB_URL", Value: "v2"},
+		}},
+		{ID: "c3", Author: "carol", Changes: []Change{
+			{Type: ChangeTypeRemove, Key: "TOKEN"},
+			{Type: ChangeTypeAdd, Key: "API_KEY", Value: "k1"},
+		}},
+	}
+
+	history := KeyHistory(chain, "DB_URL")
+	require.Len(t, history, 2)
+	assert.Equal(t, 1, history[0].Version)
+	assert.Equal(t, "c1", history[0].Commit.ID)
+	assert.Equal(t, 2, history[1].Version)
+	assert.Equal(t, "v2", history[1].Change.Value)
+
+	assert.Len(t, KeyHistory(chain, "TOKEN"), 2, "Removals should be part of a key's history")
+	assert.Empty(t, KeyHistory(chain, "MISSING"))
+
+	blame := Blame(chain)
+	require.Len(t, blame, 2, "Removed keys should not be blamed")
+	assert.Equal(t, "API_KEY", blame[0].Change.Key)
+	assert.Equal(t, "carol", blame[0].Commit.Author)
+	assert.Equal(t, "DB_URL", blame[1].Change.Key)
+	assert.Equal(t, "bob", blame[1].Commit.Author)
+	assert.Equal(t, 2, blame[1].Version)
+}
+
+func TestBlameHistoryFollowsMergedChanges(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "base", Author: "alice", Changes: []Change{{Type: ChangeTypeAdd, Key: "A", Value: "a1"}}},
+		{ID: "local", Author: "alice", ParentID: "base", Changes: []Change{{Type: ChangeTypeAdd, Key: "L", Value: "l1"}}},
+		{ID: "remote", Author: "bob", ParentID: "base", Changes: []Change{{Type: ChangeTypeModify, Key: "A", Value: "a2"}}},
+		{ID: "merge", Author: "alice", ParentID: "local", MergeParentID: "remote", Changes: []Change{
+			{Type: ChangeTypeModify, Key: "A", Value: "a2"},
+			{Type: ChangeTypeAdd, Key: "M", Value: "m1"},
+		}},
+	}))
+
+	blame, err := svc.BlameHistory("dev", "merge")
+	require.NoError(t, err)
+	require.Len(t, blame, 3)
+	assert.Equal(t, "remote", blame[0].Commit.ID, "A change taken from the merged side should be credited to its commit")
+	assert.Equal(t, "bob", blame[0].Commit.Author)
+	assert.Equal(t, "local", blame[1].Commit.ID)
+	assert.Equal(t, "merge", blame[2].Commit.ID, "Changes made in the merge itself stay with the merge")
+}
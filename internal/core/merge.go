@@ -0,0 +1,136 @@
+package core
+
+import (
+	"sort"
+)
+
+// MergeConflict is a key changed differently on both sides of a merge.
+// A nil side means the key does not exist there.
+type MergeConflict struct {
+	Key    string
+	Base   *Secret
+	Ours   *Secret
+	Theirs *Secret
+}
+
+// MergeResult is the outcome of a three-way merge. State holds the merged
+// secrets, with our value for every conflicting key until it is resolved.
+type MergeResult struct {
+	State     map[string]Secret
+	Conflicts []MergeConflict
+}
+
+// ThreeWayMerge merges two states that evolved from a common base, key by key.
+// A key changed on one side only takes that side's value; keys changed on both
+// sides to different values are reported as conflicts.
+func ThreeWayMerge(base, ours, theirs map[string]Secret) MergeResult {
+	keys := make(map[string]bool)
+	for _, state := range []map[string]Secret{base, ours, theirs} {
+		for key := range state {
+			keys[key] = true
+		}
+	}
+
+	result := MergeResult{State: make(map[string]Secret)}
+	for key := range keys {
+		b := secretAt(base, key)
+		o := secretAt(ours, key)
+		t := secretAt(theirs, key)
+
+		var merged *Secret
+		switch {
+		case sameSecret(o, t), sameSecret(b, t):
+			merged = o
+		case sameSecret(b, o):
+			merged = t
+		default:
+			result.Conflicts = append(result.Conflicts, MergeConflict{Key: key, Base: b, Ours: o, Theirs: t})
+			merged = o
+		}
+		if merged != nil {
+			result.State[key] = *merged
+		}
+	}
+
+	sort.Slice(result.Conflicts, func(i, j int) bool {
+		return result.Conflicts[i].Key < result.Conflicts[j].Key
+	})
+	return result
+}
+
+// Resolve settles a conflict by taking one side's value; a nil value removes the key
+func (r *MergeResult) Resolve(key string, value *Secret) {
+	if value == nil {
+		delete(r.State, key)
+	} else {
+		r.State[key] = *value
+	}
+	for i, conflict := range r.Conflicts {
+		if conflict.Key == key {
+			r.Conflicts = append(r.Conflicts[:i], r.Conflicts[i+1:]...)
+			return
+		}
+	}
+}
+
+func secretAt(state map[string]Secret, key string) *Secret {
+	secret, exists := state[key]
+	if !exists {
+		return nil
+	}
+	return &secret
+}
+
+func sameSecret(a, b *Secret) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return sameSecretValue(*a, *b)
+}
+
+// MergeBase returns the nearest commit that is an ancestor of both a and b,
+// following both parents of merge commits. It returns an empty ID when the
+// histories only meet before the first commit.
+func (s *commitService) MergeBase(env, a, b string) (string, error) {
+	commitMap, err := s.commitMap(env)
+	if err != nil {
+		return "", err
+	}
+
+	ancestorsOfA := ancestors(commitMap, a)
+	queue := []string{b}
+	visited := make(map[string]bool)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == "" || visited[id] {
+			continue
+		}
+		visited[id] = true
+		if ancestorsOfA[id] {
+			return id, nil
+		}
+		if commit, exists := commitMap[id]; exists {
+			queue = append(queue, commit.ParentID, commit.MergeParentID)
+		}
+	}
+	return "", nil
+}
+
+// ancestors returns the IDs reachable from a commit (inclusive) through both parents
+func ancestors(commitMap map[string]Commit, fromID string) map[string]bool {
+	reachable := make(map[string]bool)
+	stack := []string{fromID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == "" || reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		if commit, exists := commitMap[id]; exists {
+			stack = append(stack, commit.ParentID, commit.MergeParentID)
+		}
+	}
+	return reachable
+}
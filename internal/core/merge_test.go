@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	base := map[string]Secret{
+		"SAME":     {Key: "SAME", Value: "s"},
+		"OURS":     {Key: "OURS", Value: "o1"},
+		"THEIRS":   {Key: "THEIRS", Value: "t1"},
+		"BOTH":     {Key: "BOTH", Value: "b1"},
+		"REMOVED":  {Key: "REMOVED", Value: "r"},
+		"CONFLICT": {Key: "CONFLICT", Value: "c1"},
+	}
+	ours := map[string]Secret{
+		"SAME":     {Key: "SAME", Value: "s"},
+		"OURS":     {Key: "OURS", Value: "o2"},
+		"THEIRS":   {Key: "THEIRS", Value: "t1"},
+		"BOTH":     {Key: "BOTH", Value: "b2"},
+		"REMOVED":  {Key: "REMOVED", Value: "r"},
+		"CONFLICT": {Key: "CONFLICT", Value: "c-ours"},
+		"NEW":      {Key: "NEW", Value: "n"},
+	}
+	theirs := map[string]Secret{
+		"SAME":     {Key: "SAME", Value: "s"},
+		"OURS":     {Key: "OURS", Value: "o1"},
+		"THEIRS":   {Key: "THEIRS", Value: "t2"},
+		"BOTH":     {Key: "BOTH", Value: "b2"},
+		"CONFLICT": {Key: "CONFLICT", Value: "c-theirs"},
+	}
+
+	result := ThreeWayMerge(base, ours, theirs)
+
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "CONFLICT", result.Conflicts[0].Key)
+	assert.Equal(t, "c1", result.Conflicts[0].Base.Value)
+
+	assert.Equal(t, "o2", result.State["OURS"].Value, "Local-only changes should be kept")
+	assert.Equal(t, "t2", result.State["THEIRS"].Value, "Remote-only changes should be taken")
+	assert.Equal(t, "b2", result.State["BOTH"].Value, "Identical changes should merge cleanly")
+	assert.Equal(t, "n", result.State["NEW"].Value)
+	assert.NotContains(t, result.State, "REMOVED", "Remote removals should be applied")
+	assert.Equal(t, "c-ours", result.State["CONFLICT"].Value, "Conflicts default to our value")
+
+	theirValue := result.Conflicts[0].Theirs
+	result.Resolve("CONFLICT", theirValue)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, "c-theirs", result.State["CONFLICT"].Value)
+}
+
+func TestMergeBase(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "root"},
+		{ID: "base", ParentID: "root"},
+		{ID: "local", ParentID: "base"},
+		{ID: "remote1", ParentID: "base"},
+		{ID: "remote2", ParentID: "remote1"},
+		{ID: "merge", ParentID: "local", MergeParentID: "remote1"},
+	}))
+
+	base, err := svc.MergeBase("dev", "local", "remote2")
+	require.NoError(t, err)
+	assert.Equal(t, "base", base)
+
+	base, err = svc.MergeBase("dev", "merge", "remote2")
+	require.NoError(t, err)
+	assert.Equal(t, "remote1", base, "Merge parents should count as ancestors")
+}
+
+func TestMergeCommitID(t *testing.T) {
+	changes := []Change{{Type: ChangeTypeModify, Key: "A", Value: "v"}}
+	plain := CommitContentID(Commit{ParentID: "p", Changes: changes})
+	merge := CommitContentID(Commit{ParentID: "p", MergeParentID: "m", Changes: changes})
+
+	assert.Equal(t, ComputeCommitID("p", changes), plain)
+	assert.NotEqual(t, plain, merge, "The second parent should be part of a merge commit's ID")
+}
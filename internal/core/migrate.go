@@ -0,0 +1,161 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+var (
+	ErrLayoutTooNew = fmt.Errorf("the project layout is newer than this version of %s", AppName)
+)
+
+// Migration upgrades the on-disk layout of a project by one version. Apply must
+// tolerate projects that already match the new layout, such as fresh ones.
+type Migration struct {
+	Version     int // Layout version the migration produces
+	Description string
+	Apply       func(store io.Store) error
+}
+
+// migrations lists every layout change in order; migrations[i] produces version i+1
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Move pending changes out of envs/current into each environment",
+		Apply:       migratePendingChangesPerEnv,
+	},
+	{
+		Version:     2,
+		Description: "Store commits in an append-only log with an index and state snapshots",
+		Apply:       migrateCommitLog,
+	},
+}
+
+// LayoutVersion is the layout version this binary reads and writes
+var LayoutVersion = len(migrations)
+
+// layoutVersionFile is the content of ".<AppName>/version"
+type layoutVersionFile struct {
+	Version int `json:"version"`
+}
+
+type migrationService struct {
+	store io.Store
+}
+
+func NewMigrationService(workingDir string) *migrationService {
+	return NewMigrationServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewMigrationServiceWithStore creates a migration service for the project held by store
+func NewMigrationServiceWithStore(store io.Store) *migrationService {
+	return &migrationService{
+		store: store,
+	}
+}
+
+// writeLayoutVersion stamps a project with a layout version
+func writeLayoutVersion(store io.Store, version int) error {
+	if err := io.WriteJSON(store, LayoutVersionFileName, layoutVersionFile{Version: version}); err != nil {
+		return fmt.Errorf("failed to write layout version: %w", err)
+	}
+	return nil
+}
+
+// CurrentVersion returns the layout version of the project. Projects created
+// before layouts were versioned report 0.
+func (s *migrationService) CurrentVersion() (int, error) {
+	stored, err := io.ReadJSON[layoutVersionFile](s.store, LayoutVersionFileName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read layout version: %w", err)
+	}
+	return stored.Version, nil
+}
+
+// Pending returns the migrations the project still needs, oldest first. It
+// fails with ErrLayoutTooNew when the project was written by a newer binary.
+// Outside a project nothing is pending.
+func (s *migrationService) Pending() ([]Migration, error) {
+	if _, err := s.store.Stat(""); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	version, err := s.CurrentVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > LayoutVersion {
+		return nil, fmt.Errorf("%w: layout version %d, supported up to %d; upgrade %s", ErrLayoutTooNew, version, LayoutVersion, AppName)
+	}
+	return migrations[version:], nil
+}
+
+// Migrate applies the pending migrations in order, recording the new version
+// after each one so an interrupted run resumes where it stopped
+func (s *migrationService) Migrate() ([]Migration, error) {
+	pending, err := s.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		if err := migration.Apply(s.store); err != nil {
+			return pending[:i], fmt.Errorf("failed to migrate layout to version %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if err := writeLayoutVersion(s.store, migration.Version); err != nil {
+			return pending[:i], err
+		}
+	}
+	return pending, nil
+}
+
+// migratePendingChangesPerEnv moves pending changes that older versions kept in
+// the "current" file into the environment's own pending changes file
+func migratePendingChangesPerEnv(store io.Store) error {
+	_, err := NewEnvServiceWithStore(store).readCurrentFile()
+	if err != nil && !errors.Is(err, ErrCurrentEnvNotExist) {
+		return err
+	}
+	return nil
+}
+
+// migrateCommitLog converts the commit array of every environment, including
+// removed ones kept for undo, into a commit log
+func migrateCommitLog(store io.Store) error {
+	for _, parent := range []string{EnvDirPath, TrashDirPath} {
+		entries, err := store.ReadDir(parent)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", parent, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir {
+				continue
+			}
+			dir := path.Join(parent, entry.Name)
+			legacyPath := path.Join(dir, CommitFileName)
+			if _, err := store.Stat(legacyPath); errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			commits, err := io.ReadJSON[[]Commit](store, legacyPath)
+			if err != nil {
+				return err
+			}
+			if err := (commitLog{store: store, dir: dir}).replace(commits); err != nil {
+				return fmt.Errorf("failed to migrate commits of %s: %w", entry.Name, err)
+			}
+			if err := store.Remove(legacyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateUnversionedLayout(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	legacy := CurrentEnv{Env: "dev", Changes: []Change{{Type: ChangeTypeAdd, Key: "API_KEY", Value: "v"}}}
+	require.NoError(t, io.WriteJSON(envSvc.store, envSvc.currentEnvPath(), legacy))
+
+	svc := NewMigrationService(workingDir)
+	version, err := svc.CurrentVersion()
+	require.NoError(t, err)
+	assert.Zero(t, version, "Layouts without a version file predate versioning")
+
+	pending, err := svc.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, LayoutVersion)
+
+	applied, err := svc.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, pending, applied)
+
+	version, err = svc.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, LayoutVersion, version)
+
+	stored, err := io.ReadJSON[CurrentEnv](envSvc.store, envSvc.currentEnvPath())
+	require.NoError(t, err)
+	assert.Empty(t, stored.Changes)
+	changes, err := loadPendingChanges(envSvc.store, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, legacy.Changes, changes)
+
+	pending, err = svc.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending, "Migrating twice should be a no-op")
+}
+
+func TestMigrateRefusesNewerLayout(t *testing.T) {
+	workingDir := t.TempDir()
+	require.NoError(t, NewAppService(workingDir).CreateAppDir())
+
+	svc := NewMigrationService(workingDir)
+	version, err := svc.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, LayoutVersion, version, "New projects should start at the current layout")
+
+	require.NoError(t, writeLayoutVersion(ProjectStore(workingDir), LayoutVersion+1))
+	_, err = svc.Migrate()
+	assert.ErrorIs(t, err, ErrLayoutTooNew)
+}
+
+func TestMigrateOutsideProject(t *testing.T) {
+	pending, err := NewMigrationService(t.TempDir()).Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestMigrateCommitLog(t *testing.T) {
+	defer func(interval int) { snapshotInterval = interval }(snapshotInterval)
+	snapshotInterval = 2
+
+	workingDir := t.TempDir()
+	require.NoError(t, NewEnvService(workingDir).CreateEnv("dev"))
+	require.NoError(t, writeLayoutVersion(ProjectStore(workingDir), 1))
+
+	svc := NewCommitService(workingDir)
+	legacy := []Commit{
+		{ID: "c1", Changes: []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}},
+		{ID: "c2", ParentID: "c1", Changes: []Change{{Type: ChangeTypeAdd, Key: "B", Value: "2"}}},
+		{ID: "c3", ParentID: "c2", Changes: []Change{{Type: ChangeTypeRemove, Key: "A"}}},
+	}
+	legacyPath := envPath("dev", CommitFileName)
+	require.NoError(t, io.WriteJSON(svc.store, legacyPath, legacy))
+
+	_, err := NewMigrationService(workingDir).Migrate()
+	require.NoError(t, err)
+
+	_, err = svc.store.Stat(legacyPath)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = svc.store.Stat(svc.commitLog("dev").snapshotPath("c2"))
+	assert.NoError(t, err)
+	commits, err := svc.loadCommits("dev")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, commits)
+
+	state, err := svc.ComputeState("dev", "c3")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Secret{"B": {Key: "B", Value: "2"}}, state)
+}
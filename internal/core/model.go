@@ -11,6 +11,15 @@ type Project struct {
 	UpdatedAt          time.Time `json:"updatedAt"`
 
 	Key string `json:"key,omitempty"` // Base64-encoded encryption key for the project
+
+	TrustedKeys []TrustedKey `json:"trustedKeys,omitempty"` // Public keys whose commit signatures are trusted
+}
+
+// TrustedKey is a public signing key trusted to author commits in a project
+type TrustedKey struct {
+	Name      string    `json:"name"`
+	PublicKey string    `json:"publicKey"` // Base64-encoded Ed25519 public key
+	AddedAt   time.Time `json:"addedAt"`
 }
 
 type Environment struct {
@@ -36,10 +45,22 @@ type Commit struct {
 	ID        string    `json:"id"`
 	Message   string    `json:"message"`
 	Author    string    `json:"author"`
+	Committer string    `json:"committer,omitempty"` // Who replayed or rewrote the commit, when not the author
 	Timestamp time.Time `json:"timestamp"`
 	Changes   []Change  `json:"changes"`
 	ParentID  string    `json:"parentId,omitempty"` // Empty for first commit
 
+	// MergeParentID is the second parent of a merge commit: the remote head that was
+	// merged. Changes of a merge commit are relative to ParentID.
+	MergeParentID string `json:"mergeParentId,omitempty"`
+
+	// Amends is the commit an amend or squash replaced. Its ID and the message
+	// are hashed into the replacement's ID, so a reword gets a new ID.
+	Amends string `json:"amends,omitempty"`
+
+	Signature string `json:"signature,omitempty"` // Base64-encoded Ed25519 signature over the commit payload
+	SignerKey string `json:"signerKey,omitempty"` // Base64-encoded public key of the signer
+
 	ProjectID       string `json:"projectId,omitempty"`
 	EnvironmentName string `json:"environmentName,omitempty"`
 }
@@ -50,9 +71,18 @@ type Head struct {
 	RemoteHead string `json:"remoteHead"` // Latest remote commit ID
 }
 
+// Tag is a named reference to a commit, e.g. a known-good release
+type Tag struct {
+	Name      string    `json:"name"`
+	CommitID  string    `json:"commitId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type CurrentEnv struct {
-	Env     string   `json:"env"`
-	Changes []Change `json:"changes"`
+	Env string `json:"env"`
+	// Changes holds the pending changes of Env. They are stored per environment;
+	// older versions kept them in the "current" file itself.
+	Changes []Change `json:"changes,omitempty"`
 }
 
 // ChangeType represents the type of change made to a secret
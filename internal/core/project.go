@@ -2,19 +2,23 @@ package core
 
 import (
 	"fmt"
-	"path/filepath"
 	"time"
 
 	"github.com/jawahars16/jebi/internal/io"
 )
 
 type projectService struct {
-	workingDir string
+	store io.Store
 }
 
 func NewProjectService(workingDir string) *projectService {
+	return NewProjectServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewProjectServiceWithStore creates a project service backed by store
+func NewProjectServiceWithStore(store io.Store) *projectService {
 	return &projectService{
-		workingDir: workingDir,
+		store: store,
 	}
 }
 
@@ -27,8 +31,7 @@ func (p *projectService) SaveProjectConfig(id, name, description, defaultEnviron
 		CreatedAt:          time.Now().UTC(),
 		UpdatedAt:          time.Now().UTC(),
 	}
-	path := filepath.Join(p.workingDir, fmt.Sprintf(".%s", AppName), ProjectConfigFile)
-	err := io.WriteJSONToFile(path, project)
+	err := io.WriteJSON(p.store, ProjectConfigFile, project)
 	if err != nil {
 		return "", fmt.Errorf("failed to write project config: %w", err)
 	}
@@ -36,10 +39,18 @@ func (p *projectService) SaveProjectConfig(id, name, description, defaultEnviron
 }
 
 func (p *projectService) LoadProjectConfig() (*Project, error) {
-	path := filepath.Join(p.workingDir, fmt.Sprintf(".%s", AppName), ProjectConfigFile)
-	project, err := io.ReadJSONFile[Project](path)
+	project, err := io.ReadJSON[Project](p.store, ProjectConfigFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read project config: %w", err)
 	}
 	return &project, nil
 }
+
+// UpdateProjectConfig persists changes to an existing project configuration
+func (p *projectService) UpdateProjectConfig(project *Project) error {
+	project.UpdatedAt = time.Now().UTC()
+	if err := io.WriteJSON(p.store, ProjectConfigFile, project); err != nil {
+		return fmt.Errorf("failed to write project config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+var (
+	ErrRebaseInProgress = fmt.Errorf("a rebase is in progress")
+	ErrNoRebase         = fmt.Errorf("no rebase in progress")
+)
+
+// RebaseState tracks a rebase of local commits onto a newer remote head so it
+// can be continued after a conflict or aborted
+type RebaseState struct {
+	OriginalHead       string   `json:"originalHead"`       // Local HEAD before the rebase
+	OriginalRemoteHead string   `json:"originalRemoteHead"` // Remote HEAD before the rebase
+	Onto               string   `json:"onto"`               // Remote head the commits are replayed on
+	Remaining          []Commit `json:"remaining"`          // Original commits still to replay, oldest first
+	Stopped            bool     `json:"stopped,omitempty"`  // Whether Remaining[0] is staged awaiting --continue
+	Replayed           int      `json:"replayed"`
+	Tags               []Tag    `json:"tags,omitempty"` // Fetched tags to import once done
+}
+
+func (s *commitService) getRebasePath(env string) string {
+	return envPath(env, RebaseFileName)
+}
+
+// GetRebaseState returns the rebase in progress, or ErrNoRebase
+func (s *commitService) GetRebaseState(env string) (*RebaseState, error) {
+	path := s.getRebasePath(env)
+	if _, err := s.store.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoRebase
+	}
+	state, err := io.ReadJSON[RebaseState](s.store, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rebase state: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveRebaseState records the progress of a rebase
+func (s *commitService) SaveRebaseState(env string, state RebaseState) error {
+	if err := io.WriteJSON(s.store, s.getRebasePath(env), state); err != nil {
+		return fmt.Errorf("failed to write rebase state: %w", err)
+	}
+	return nil
+}
+
+// ClearRebaseState ends a rebase
+func (s *commitService) ClearRebaseState(env string) error {
+	if err := s.store.Remove(s.getRebasePath(env)); err != nil {
+		return fmt.Errorf("failed to remove rebase state: %w", err)
+	}
+	return nil
+}
+
+// ReplayChanges computes the changes that re-apply a commit on top of the current
+// state. parentState is the state the commit was originally made on. Keys the
+// commit changed that also changed between parentState and current, to a value
+// other than the commit's, are returned as conflicts.
+func ReplayChanges(commit Commit, parentState, current map[string]Secret) ([]Change, []string) {
+	target := make(map[string]Secret, len(current))
+	for key, secret := range current {
+		target[key] = secret
+	}
+	ApplyChangesToState(target, commit.Changes)
+
+	var conflicts []string
+	for _, change := range commit.Changes {
+		before := secretAt(parentState, change.Key)
+		now := secretAt(current, change.Key)
+		wanted := secretAt(target, change.Key)
+		if !sameSecret(before, now) && !sameSecret(now, wanted) {
+			conflicts = append(conflicts, change.Key)
+		}
+	}
+	sort.Strings(conflicts)
+
+	return ChangesBetween(current, target), conflicts
+}
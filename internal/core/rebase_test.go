@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayChanges(t *testing.T) {
+	parent := map[string]Secret{
+		"A": {Key: "A", Value: "a1"},
+		"B": {Key: "B", Value: "b1"},
+	}
+	// The remote changed B and added C since the commit was made
+	current := map[string]Secret{
+		"A": {Key: "A", Value: "a1"},
+		"B": {Key: "B", Value: "b-remote"},
+		"C": {Key: "C", Value: "c1"},
+	}
+
+	clean := Commit{Changes: []Change{
+		{Type: ChangeTypeModify, Key: "A", Value: "a2"},
+		{Type: ChangeTypeAdd, Key: "D", Value: "d1"},
+	}}
+	changes, conflicts := ReplayChanges(clean, parent, current)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, []Change{
+		{Type: ChangeTypeModify, Key: "A", Value: "a2"},
+		{Type: ChangeTypeAdd, Key: "D", Value: "d1"},
+	}, changes)
+
+	conflicting := Commit{Changes: []Change{{Type: ChangeTypeModify, Key: "B", Value: "b-local"}}}
+	_, conflicts = ReplayChanges(conflicting, parent, current)
+	assert.Equal(t, []string{"B"}, conflicts)
+
+	sameAsRemote := Commit{Changes: []Change{{Type: ChangeTypeModify, Key: "B", Value: "b-remote"}}}
+	changes, conflicts = ReplayChanges(sameAsRemote, parent, current)
+	assert.Empty(t, conflicts, "Identical changes on both sides are not conflicts")
+	assert.Empty(t, changes, "Commits already applied upstream become empty")
+}
+
+func TestReplayCommitRecordsCommitter(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	original := Commit{ID: "orig00000000", Message: "add A", Author: "alice", Timestamp: time.Now().Add(-time.Hour)}
+	changes := []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}
+
+	replayed, err := svc.ReplayCommit("dev", original, "bob", changes)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", replayed.Author)
+	assert.Equal(t, "bob", replayed.Committer, "The rebaser signs the commit and should be recorded")
+	assert.Equal(t, original.Message, replayed.Message)
+	assert.True(t, original.Timestamp.Equal(replayed.Timestamp))
+
+	issues, err := svc.CheckHistory("dev")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	own, err := svc.ReplayCommit("dev", Commit{Message: "add B", Author: "bob"}, "bob", []Change{{Type: ChangeTypeAdd, Key: "B", Value: "2"}})
+	require.NoError(t, err)
+	assert.Empty(t, own.Committer, "Replaying one's own commit needs no committer")
+}
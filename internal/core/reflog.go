@@ -0,0 +1,546 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+// maxReflogEntries is how many operations the reflog keeps; older entries and
+// the trash only they refer to are dropped
+const maxReflogEntries = 1000
+
+var (
+	ErrNothingToUndo = fmt.Errorf("nothing to undo")
+	ErrStateChanged  = fmt.Errorf("the workspace changed since the operation was recorded")
+)
+
+// EnvSnapshot is the mutable state of one environment at a point in time. The
+// secrets file is not stored: it is the committed state plus the pending changes.
+type EnvSnapshot struct {
+	Head    Head     `json:"head"`
+	Pending []Change `json:"pending,omitempty"`
+	Tags    []Tag    `json:"tags,omitempty"`
+}
+
+// Snapshot is the mutable state of the whole workspace at a point in time
+type Snapshot struct {
+	CurrentEnv string                 `json:"currentEnv,omitempty"`
+	Envs       map[string]EnvSnapshot `json:"envs,omitempty"`
+	Stash      []StashEntry           `json:"stash,omitempty"`
+
+	takenAt time.Time // When the snapshot was captured; only trash from after it belongs to the operation
+}
+
+// ReflogEntry records one operation with the workspace state before and after
+// it. Only environments the operation changed are kept in the snapshots; one
+// missing from After was removed and one missing from Before was created.
+type ReflogEntry struct {
+	ID      int       `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Before  Snapshot  `json:"before"`
+	After   Snapshot  `json:"after"`
+
+	// StashUnchanged is set when the operation left the stash alone, in which
+	// case neither snapshot holds it
+	StashUnchanged bool `json:"stashUnchanged,omitempty"`
+
+	// Trash maps environments removed by the operation to where they were kept
+	Trash map[string]string `json:"trash,omitempty"`
+
+	// Renamed maps environments the operation renamed to their previous names
+	Renamed map[string]string `json:"renamed,omitempty"`
+
+	// Undoes is the ID of the entry this operation undid
+	Undoes int `json:"undoes,omitempty"`
+}
+
+type reflogService struct {
+	store io.Store
+}
+
+func NewReflogService(workingDir string) *reflogService {
+	return NewReflogServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewReflogServiceWithStore creates a reflog service for the project held by store
+func NewReflogServiceWithStore(store io.Store) *reflogService {
+	return &reflogService{
+		store: store,
+	}
+}
+
+// trashPath returns the store name of a trash directory. Entries written by
+// earlier versions recorded it as an absolute path.
+func trashPath(trash string) string {
+	return path.Join(TrashDirPath, filepath.Base(trash))
+}
+
+// Snapshot captures the current workspace state. It is empty outside a project.
+func (s *reflogService) Snapshot() (Snapshot, error) {
+	snapshot := Snapshot{takenAt: time.Now()}
+
+	entries, err := s.store.ReadDir(EnvDirPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	current, err := io.ReadJSON[CurrentEnv](s.store, NewEnvServiceWithStore(s.store).currentEnvPath())
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.CurrentEnv = current.Env
+
+	commits := NewCommitServiceWithStore(s.store, nil)
+	for _, entry := range entries {
+		if !entry.IsDir {
+			continue
+		}
+		env := entry.Name
+
+		head, err := commits.GetHead(env)
+		if err != nil {
+			return snapshot, err
+		}
+		pending, err := loadPendingChanges(s.store, env)
+		if err != nil {
+			return snapshot, err
+		}
+		tags, err := commits.ListTags(env)
+		if err != nil {
+			return snapshot, err
+		}
+
+		if snapshot.Envs == nil {
+			snapshot.Envs = make(map[string]EnvSnapshot)
+		}
+		envSnapshot := EnvSnapshot{Head: *head}
+		if len(pending) > 0 {
+			envSnapshot.Pending = pending
+		}
+		if len(tags) > 0 {
+			envSnapshot.Tags = tags
+		}
+		snapshot.Envs[env] = envSnapshot
+	}
+
+	stash, err := NewStashServiceWithStore(s.store).List()
+	if err != nil {
+		return snapshot, err
+	}
+	if len(stash) > 0 {
+		snapshot.Stash = stash
+	}
+	return snapshot, nil
+}
+
+// Record appends an entry for an operation that changed the workspace. Nothing
+// is recorded when the state is unchanged or there is no project.
+func (s *reflogService) Record(command string, before, after Snapshot, undoes int) (*ReflogEntry, error) {
+	if undoes == 0 && sameSnapshot(before, after) {
+		return nil, nil
+	}
+	if _, err := s.store.Stat(""); err != nil {
+		return nil, nil
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := ReflogEntry{
+		ID:      1,
+		Command: command,
+		Time:    time.Now(),
+		Before:  before,
+		After:   after,
+		Undoes:  undoes,
+	}
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
+	var vanished, appeared []string
+	for env := range before.Envs {
+		if _, exists := after.Envs[env]; exists {
+			continue
+		}
+		if trash := s.latestTrash(env, before.takenAt); trash != "" {
+			if entry.Trash == nil {
+				entry.Trash = make(map[string]string)
+			}
+			entry.Trash[env] = trash
+		} else {
+			vanished = append(vanished, env)
+		}
+	}
+	for env := range after.Envs {
+		if _, existed := before.Envs[env]; !existed {
+			appeared = append(appeared, env)
+		}
+	}
+	// Removed environments go to the trash, so one that vanished without going
+	// there was renamed to the one that appeared in its place
+	if len(vanished) == 1 && len(appeared) == 1 && before.Envs[vanished[0]].Head.LocalHead == after.Envs[appeared[0]].Head.LocalHead {
+		entry.Renamed = map[string]string{appeared[0]: vanished[0]}
+	}
+	entry.Before, entry.After, entry.StashUnchanged = changedOnly(before, after)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode reflog entry: %w", err)
+	}
+	if len(entries) >= maxReflogEntries {
+		if err := s.prune(entries[len(entries)-maxReflogEntries+1:], entries[:len(entries)-maxReflogEntries+1], line); err != nil {
+			return nil, err
+		}
+		return &entry, nil
+	}
+	if err := s.store.AppendFile(ReflogFileName, append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write reflog: %w", err)
+	}
+	return &entry, nil
+}
+
+// changedOnly strips two snapshots down to the environments and stash that
+// differ between them. It reports whether the stash was left out.
+func changedOnly(before, after Snapshot) (Snapshot, Snapshot, bool) {
+	keptBefore := Snapshot{CurrentEnv: before.CurrentEnv, Stash: before.Stash}
+	keptAfter := Snapshot{CurrentEnv: after.CurrentEnv, Stash: after.Stash}
+	keep := func(snapshot *Snapshot, env string, envSnapshot EnvSnapshot) {
+		if snapshot.Envs == nil {
+			snapshot.Envs = make(map[string]EnvSnapshot)
+		}
+		snapshot.Envs[env] = envSnapshot
+	}
+
+	for env, old := range before.Envs {
+		now, exists := after.Envs[env]
+		if exists && sameJSON(old, now) {
+			continue
+		}
+		keep(&keptBefore, env, old)
+		if exists {
+			keep(&keptAfter, env, now)
+		}
+	}
+	for env, now := range after.Envs {
+		if _, existed := before.Envs[env]; !existed {
+			keep(&keptAfter, env, now)
+		}
+	}
+
+	stashUnchanged := sameJSON(before.Stash, after.Stash)
+	if stashUnchanged {
+		keptBefore.Stash, keptAfter.Stash = nil, nil
+	}
+	return keptBefore, keptAfter, stashUnchanged
+}
+
+// prune rewrites the reflog with the kept entries followed by line, and empties
+// the trash of environments only the dropped entries could restore
+func (s *reflogService) prune(kept, dropped []ReflogEntry, line []byte) error {
+	var content []byte
+	referenced := make(map[string]bool)
+	for _, entry := range kept {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode reflog entry: %w", err)
+		}
+		content = append(append(content, encoded...), '\n')
+		for _, trash := range entry.Trash {
+			referenced[trash] = true
+		}
+	}
+	content = append(append(content, line...), '\n')
+
+	if err := s.store.WriteFile(ReflogFileName, content); err != nil {
+		return fmt.Errorf("failed to write reflog: %w", err)
+	}
+	for _, entry := range dropped {
+		for _, trash := range entry.Trash {
+			if referenced[trash] {
+				continue
+			}
+			if err := s.store.RemoveAll(trashPath(trash)); err != nil {
+				return fmt.Errorf("failed to empty trash: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// List returns every reflog entry, oldest first
+func (s *reflogService) List() ([]ReflogEntry, error) {
+	data, err := s.store.ReadFile(ReflogFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflog: %w", err)
+	}
+
+	var entries []ReflogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry ReflogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse reflog entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reflog: %w", err)
+	}
+	return entries, nil
+}
+
+// NextUndo returns the newest operation that has not been undone yet. Undo
+// operations themselves are skipped, so repeated undos walk further back.
+func (s *reflogService) NextUndo() (*ReflogEntry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	undone := make(map[int]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Undoes != 0 {
+			undone[entry.Undoes] = true
+			continue
+		}
+		if !undone[entry.ID] {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNothingToUndo
+}
+
+// CheckUndo verifies that an entry can be undone: what the operation changed
+// must still be as it left it, and remote HEADs must not have moved, since the
+// remote keeps what was pushed or pulled
+func (s *reflogService) CheckUndo(entry ReflogEntry) error {
+	for env, before := range entry.Before.Envs {
+		after, exists := entry.After.Envs[env]
+		if exists && before.Head.RemoteHead != after.Head.RemoteHead {
+			return fmt.Errorf("cannot undo '%s': it synchronized '%s' with the remote", entry.Command, env)
+		}
+	}
+
+	current, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	if !matchesAfter(current, entry) {
+		return fmt.Errorf("%w: '%s'", ErrStateChanged, entry.Command)
+	}
+	return nil
+}
+
+// matchesAfter reports whether the parts of the workspace an entry recorded
+// are still in the state its operation left them in
+func matchesAfter(current Snapshot, entry ReflogEntry) bool {
+	if current.CurrentEnv != entry.After.CurrentEnv {
+		return false
+	}
+	if !entry.StashUnchanged && !sameJSON(current.Stash, entry.After.Stash) {
+		return false
+	}
+
+	recorded := make(map[string]bool)
+	for env := range entry.Before.Envs {
+		recorded[env] = true
+	}
+	for env := range entry.After.Envs {
+		recorded[env] = true
+	}
+	for env := range recorded {
+		now, exists := current.Envs[env]
+		then, existed := entry.After.Envs[env]
+		if exists != existed || (exists && !sameJSON(now, then)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Restore puts the workspace back into the state captured before an entry's
+// operation. Environments the operation created are moved to the trash,
+// renamed ones get their old names back and removed ones are brought back
+// from the trash. Nothing is changed unless all of that is possible. Secrets
+// files are not touched; callers rebuild them from the restored HEADs and
+// pending changes.
+func (s *reflogService) Restore(entry ReflogEntry) error {
+	target := entry.Before
+
+	current, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	renameBack := make(map[string]string)
+	renamedTo := make(map[string]bool)
+	for newName, oldName := range entry.Renamed {
+		_, exists := current.Envs[newName]
+		_, taken := current.Envs[oldName]
+		if exists && !taken {
+			renameBack[newName] = oldName
+			renamedTo[oldName] = true
+		}
+	}
+	var created []string
+	for env := range entry.After.Envs {
+		_, keep := target.Envs[env]
+		_, exists := current.Envs[env]
+		if _, renamed := renameBack[env]; !keep && !renamed && exists {
+			created = append(created, env)
+		}
+	}
+	removed := make(map[string]string)
+	for env := range target.Envs {
+		if _, exists := current.Envs[env]; exists || renamedTo[env] {
+			continue
+		}
+		trash, ok := entry.Trash[env]
+		if !ok {
+			return fmt.Errorf("environment '%s' cannot be restored: it is not in the trash", env)
+		}
+		if _, err := s.store.Stat(trashPath(trash)); err != nil {
+			return fmt.Errorf("environment '%s' cannot be restored: %w", env, err)
+		}
+		removed[env] = trashPath(trash)
+	}
+
+	for _, env := range created {
+		if err := moveToTrash(s.store, env); err != nil {
+			return fmt.Errorf("failed to remove environment '%s': %w", env, err)
+		}
+	}
+	commits := NewCommitServiceWithStore(s.store, nil)
+	for newName, oldName := range renameBack {
+		if err := s.renameBack(commits, newName, oldName); err != nil {
+			return err
+		}
+	}
+	for env, trash := range removed {
+		if err := s.store.Rename(trash, envPath(env)); err != nil {
+			return fmt.Errorf("failed to restore environment '%s': %w", env, err)
+		}
+	}
+
+	for env, snapshot := range target.Envs {
+		if err := io.WriteJSON(commits.store, commits.getHeadPath(env), snapshot.Head); err != nil {
+			return fmt.Errorf("failed to restore HEAD of '%s': %w", env, err)
+		}
+		if err := savePendingChanges(s.store, env, snapshot.Pending); err != nil {
+			return err
+		}
+		if err := commits.saveTags(env, snapshot.Tags); err != nil {
+			return err
+		}
+	}
+
+	if target.CurrentEnv == "" {
+		if err := s.store.Remove(NewEnvServiceWithStore(s.store).currentEnvPath()); err != nil {
+			return fmt.Errorf("failed to unset current environment: %w", err)
+		}
+	} else if err := io.WriteJSON(s.store, NewEnvServiceWithStore(s.store).currentEnvPath(), CurrentEnv{Env: target.CurrentEnv}); err != nil {
+		return fmt.Errorf("failed to restore current environment: %w", err)
+	}
+
+	if entry.StashUnchanged {
+		return nil
+	}
+	return NewStashServiceWithStore(s.store).save(target.Stash)
+}
+
+// renameBack gives a renamed environment its previous name again, relabelling
+// its commits and the project's default environment
+func (s *reflogService) renameBack(commits *commitService, newName, oldName string) error {
+	if err := s.store.Rename(envPath(newName), envPath(oldName)); err != nil {
+		return fmt.Errorf("failed to rename environment '%s' back to '%s': %w", newName, oldName, err)
+	}
+	if err := commits.RelabelCommits(oldName, newName); err != nil {
+		return fmt.Errorf("failed to update the commits of '%s': %w", oldName, err)
+	}
+
+	projects := NewProjectServiceWithStore(s.store)
+	project, err := projects.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project.DefaultEnvironment == newName {
+		project.DefaultEnvironment = oldName
+		if err := projects.UpdateProjectConfig(project); err != nil {
+			return fmt.Errorf("failed to update the default environment: %w", err)
+		}
+	}
+	return nil
+}
+
+// latestTrash returns the most recent trash directory holding env that was
+// created after since
+func (s *reflogService) latestTrash(env string, since time.Time) string {
+	entries, err := s.store.ReadDir(TrashDirPath)
+	if err != nil {
+		return ""
+	}
+
+	latest, latestAt := "", int64(0)
+	if !since.IsZero() {
+		latestAt = since.UnixNano() - 1
+	}
+	for _, entry := range entries {
+		name, stamp, ok := strings.Cut(entry.Name, "@")
+		if !ok || name != env {
+			continue
+		}
+		at, err := strconv.ParseInt(stamp, 10, 64)
+		if err == nil && at > latestAt {
+			latest, latestAt = path.Join(TrashDirPath, entry.Name), at
+		}
+	}
+	return latest
+}
+
+// moveToTrash moves an environment directory into the trash, named after the
+// environment and the time it was removed
+func moveToTrash(store io.Store, env string) error {
+	if err := store.MkdirAll(TrashDirPath); err != nil {
+		return err
+	}
+	return store.Rename(envPath(env), path.Join(TrashDirPath, fmt.Sprintf("%s@%d", env, time.Now().UnixNano())))
+}
+
+// sameSnapshot reports whether two snapshots describe the same state
+func sameSnapshot(a, b Snapshot) bool {
+	return sameJSON(a, b)
+}
+
+// sameJSON compares two values by their JSON form, so that values read back
+// from the reflog compare equal to freshly captured ones
+func sameJSON(a, b any) bool {
+	// Marshalling plain structs, slices and string-keyed maps cannot fail
+	encodedA, _ := json.Marshal(a)
+	encodedB, _ := json.Marshal(b)
+	return string(encodedA) == string(encodedB)
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReflogUndo(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	changeSvc := NewChangeRecordService(workingDir)
+	commitSvc := NewCommitService(workingDir)
+	reflog := NewReflogService(workingDir)
+
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	require.NoError(t, envSvc.CreateEnv("prod"))
+	require.NoError(t, envSvc.SetCurrentEnv("dev"))
+	require.NoError(t, changeSvc.AddChangeRecord("dev", string(ChangeTypeAdd), "API_KEY", "v", "n", false))
+
+	before, err := reflog.Snapshot()
+	require.NoError(t, err)
+	entry, err := reflog.Record("status", before, before, 0)
+	require.NoError(t, err)
+	assert.Nil(t, entry, "Operations that change nothing should not be recorded")
+
+	pending, err := changeSvc.GetPendingChanges("dev")
+	require.NoError(t, err)
+	_, err = commitSvc.AddCommit("", "dev", "add key", "alice", pending, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, changeSvc.ClearPendingChanges("dev"))
+	afterCommit, err := reflog.Snapshot()
+	require.NoError(t, err)
+	_, err = reflog.Record("commit", before, afterCommit, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, envSvc.RemoveEnv("prod"))
+	afterRemove, err := reflog.Snapshot()
+	require.NoError(t, err)
+	_, err = reflog.Record("env remove", afterCommit, afterRemove, 0)
+	require.NoError(t, err)
+
+	// Undo the removal: the environment comes back from the trash
+	next, err := reflog.NextUndo()
+	require.NoError(t, err)
+	assert.Equal(t, "env remove", next.Command)
+	require.NoError(t, reflog.CheckUndo(*next))
+	require.NoError(t, reflog.Restore(*next))
+	exists, err := envSvc.EnvExists("prod")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	restored, err := reflog.Snapshot()
+	require.NoError(t, err)
+	_, err = reflog.Record("undo", afterRemove, restored, next.ID)
+	require.NoError(t, err)
+
+	// The next undo walks back past the undone entry to the commit
+	next, err = reflog.NextUndo()
+	require.NoError(t, err)
+	assert.Equal(t, "commit", next.Command)
+	require.NoError(t, reflog.CheckUndo(*next))
+	require.NoError(t, reflog.Restore(*next))
+
+	head, err := commitSvc.GetHead("dev")
+	require.NoError(t, err)
+	assert.Empty(t, head.LocalHead)
+	pending, err = changeSvc.GetPendingChanges("dev")
+	require.NoError(t, err)
+	assert.Len(t, pending, 1, "Pending changes from before the commit should be back")
+}
+
+func TestReflogRefusesStaleOrSyncedUndo(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	commitSvc := NewCommitService(workingDir)
+	changeSvc := NewChangeRecordService(workingDir)
+	reflog := NewReflogService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+
+	before, err := reflog.Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, commitSvc.UpdateRemoteHead("dev", "abc"))
+	after, err := reflog.Snapshot()
+	require.NoError(t, err)
+	entry, err := reflog.Record("push", before, after, 0)
+	require.NoError(t, err)
+	assert.Error(t, reflog.CheckUndo(*entry), "Remote synchronization cannot be undone locally")
+
+	require.NoError(t, changeSvc.AddChangeRecord("dev", string(ChangeTypeAdd), "K", "v", "n", false))
+	changed, err := reflog.Snapshot()
+	require.NoError(t, err)
+	entry, err = reflog.Record("set", after, changed, 0)
+	require.NoError(t, err)
+	require.NoError(t, changeSvc.ClearPendingChanges("dev"))
+	assert.ErrorIs(t, reflog.CheckUndo(*entry), ErrStateChanged)
+}
+
+func TestReflogUndoRename(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	reflog := NewReflogService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	require.NoError(t, envSvc.CreateEnv("staging"))
+	require.NoError(t, envSvc.SetCurrentEnv("staging"))
+
+	before, err := reflog.Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, envSvc.RenameEnv("staging", "qa"))
+	after, err := reflog.Snapshot()
+	require.NoError(t, err)
+	entry, err := reflog.Record("env rename", before, after, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"qa": "staging"}, entry.Renamed)
+	assert.NotContains(t, entry.Before.Envs, "dev", "Unchanged environments should not be recorded")
+	assert.True(t, entry.StashUnchanged)
+
+	require.NoError(t, reflog.CheckUndo(*entry))
+	require.NoError(t, reflog.Restore(*entry))
+	envs, err := envSvc.ListEnvs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev", "staging"}, envs)
+	current, err := envSvc.CurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "staging", current)
+}
+
+func TestReflogRestoreChecksBeforeChanging(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	reflog := NewReflogService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	require.NoError(t, envSvc.SetCurrentEnv("dev"))
+
+	before, err := reflog.Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, envSvc.CreateEnv("qa"))
+	after, err := reflog.Snapshot()
+	require.NoError(t, err)
+
+	// An entry whose removed environment is gone from the trash
+	entry := ReflogEntry{
+		Command: "env remove",
+		Before:  Snapshot{CurrentEnv: "dev", Envs: map[string]EnvSnapshot{"dev": before.Envs["dev"], "prod": {}}},
+		After:   Snapshot{CurrentEnv: "dev", Envs: map[string]EnvSnapshot{"dev": after.Envs["dev"], "qa": after.Envs["qa"]}},
+		Trash:   map[string]string{"prod": "missing"},
+	}
+	assert.Error(t, reflog.Restore(entry))
+	exists, err := envSvc.EnvExists("qa")
+	require.NoError(t, err)
+	assert.True(t, exists, "Nothing should be moved to the trash when the restore cannot complete")
+}
+
+func TestReflogIsCapped(t *testing.T) {
+	workingDir := t.TempDir()
+	envSvc := NewEnvService(workingDir)
+	reflog := NewReflogService(workingDir)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+
+	for i := 0; i < maxReflogEntries+5; i++ {
+		_, err := reflog.Record("undo", Snapshot{}, Snapshot{}, 1)
+		require.NoError(t, err)
+	}
+	entries, err := reflog.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, maxReflogEntries)
+	assert.Equal(t, maxReflogEntries+5, entries[len(entries)-1].ID, "IDs should keep counting after old entries are dropped")
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRewritePushed = fmt.Errorf("cannot rewrite commits the remote already has")
+	ErrEmptyRewrite  = fmt.Errorf("the combined changes cancel out")
+)
+
+// AmendCommit replaces the local HEAD commit with one that also carries changes
+// and, when message is non-empty, the new message. Tags on the old commit move
+// to the amended one. Commits at or before the remote HEAD cannot be amended.
+func (s *commitService) AmendCommit(env, message, author string, changes []Change, timestamp time.Time) (*Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead == "" {
+		return nil, fmt.Errorf("no commit to amend in environment %s", env)
+	}
+
+	chain, err := s.GetCommitChain(env, head.LocalHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build commit chain: %w", err)
+	}
+	last := len(chain) - 1
+	amended := chain[last]
+	if message != "" {
+		amended.Message = message
+	}
+	amended.Author = author
+	amended.Timestamp = timestamp
+	amended.Changes, err = s.combineChanges(env, amended.ParentID, append(append([]Change(nil), amended.Changes...), changes...))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(amended.Changes) == 0 {
+		return nil, fmt.Errorf("%w: amending %s would leave an empty commit", ErrEmptyRewrite, chain[last].ID)
+	}
+
+	return s.rewriteChain(env, head, chain, last, last, amended)
+}
+
+// SquashCommits replaces the commits fromID through toID (inclusive) of the
+// local history with a single commit holding their normalized changes. Later
+// commits are replayed on top with new IDs. When message is empty the messages
+// of the squashed commits are joined. Commits at or before the remote HEAD and
+// merge commits cannot be squashed.
+func (s *commitService) SquashCommits(env, fromID, toID, message, author string, timestamp time.Time) (*Commit, error) {
+	head, err := s.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	chain, err := s.GetCommitChain(env, head.LocalHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build commit chain: %w", err)
+	}
+
+	from, to := -1, -1
+	for i, commit := range chain {
+		if commit.ID == fromID {
+			from = i
+		}
+		if commit.ID == toID {
+			to = i
+		}
+	}
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("%w: both ends of the range must be on the local history of '%s'", ErrCommitNotFound, env)
+	}
+	if from > to {
+		return nil, fmt.Errorf("commit %s comes after %s; give the older commit first", fromID, toID)
+	}
+
+	var changes []Change
+	var messages []string
+	for _, commit := range chain[from : to+1] {
+		if commit.MergeParentID != "" {
+			return nil, fmt.Errorf("cannot squash merge commit %s", commit.ID)
+		}
+		changes = append(changes, commit.Changes...)
+		messages = append(messages, commit.Message)
+	}
+	if message == "" {
+		message = strings.Join(messages, "; ")
+	}
+
+	combined, err := s.combineChanges(env, chain[from].ParentID, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	squashed := Commit{
+		Message:         message,
+		Author:          author,
+		Timestamp:       timestamp,
+		Changes:         combined,
+		ParentID:        chain[from].ParentID,
+		ProjectID:       chain[to].ProjectID,
+		EnvironmentName: chain[to].EnvironmentName,
+	}
+	if len(squashed.Changes) == 0 {
+		return nil, fmt.Errorf("%w: squashing %s..%s would leave an empty commit", ErrEmptyRewrite, fromID, toID)
+	}
+
+	return s.rewriteChain(env, head, chain, from, to, squashed)
+}
+
+// rewriteChain replaces chain[from:to+1] with replacement and replays the
+// commits after it on top, then moves the local HEAD and any tags to the new
+// commits. The replaced commits stay in the store, unreachable from HEAD.
+func (s *commitService) rewriteChain(env string, head *Head, chain []Commit, from, to int, replacement Commit) (*Commit, error) {
+	if head.RemoteHead != "" {
+		commitMap, err := s.commitMap(env)
+		if err != nil {
+			return nil, err
+		}
+		if ancestors(commitMap, head.RemoteHead)[chain[from].ID] {
+			return nil, fmt.Errorf("%w: %s is at or before the remote HEAD %s", ErrRewritePushed, chain[from].ID, head.RemoteHead)
+		}
+	}
+
+	var commits []Commit
+	rewritten := make(map[string]string)
+	replacement.Amends = chain[to].ID
+	replacement.ID = CommitContentID(replacement)
+	if err := s.sign(&replacement); err != nil {
+		return nil, err
+	}
+	for _, commit := range chain[from : to+1] {
+		rewritten[commit.ID] = replacement.ID
+	}
+	commits = append(commits, replacement)
+
+	parentID := replacement.ID
+	for _, commit := range chain[to+1:] {
+		oldID := commit.ID
+		commit.ParentID = parentID
+		if commit.Author != replacement.Author {
+			commit.Committer = replacement.Author
+		}
+		commit.ID = CommitContentID(commit)
+		if err := s.sign(&commit); err != nil {
+			return nil, err
+		}
+		rewritten[oldID] = commit.ID
+		commits = append(commits, commit)
+		parentID = commit.ID
+	}
+
+	if err := s.commitLog(env).append(commits...); err != nil {
+		return nil, fmt.Errorf("failed to save commits: %w", err)
+	}
+	if err := s.UpdateLocalHead(env, parentID); err != nil {
+		return nil, fmt.Errorf("failed to update HEAD: %w", err)
+	}
+
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+	for i, tag := range tags {
+		if newID, ok := rewritten[tag.CommitID]; ok {
+			tags[i].CommitID = newID
+		}
+	}
+	if err := s.saveTags(env, tags); err != nil {
+		return nil, err
+	}
+
+	return &replacement, nil
+}
+
+// combineChanges normalizes a sequence of changes into one change per key,
+// typed as add or modify against the state at parentID, sorted by key
+func (s *commitService) combineChanges(env, parentID string, changes []Change) ([]Change, error) {
+	parentState, err := s.ComputeState(env, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute state at %s: %w", parentID, err)
+	}
+
+	var combined []Change
+	for _, change := range normalizeChanges(changes) {
+		_, existed := parentState[change.Key]
+		switch {
+		case change.Type == ChangeTypeRemove && !existed:
+			// Added and removed again within the combined commits
+			continue
+		case change.Type == ChangeTypeRemove:
+		case existed:
+			change.Type = ChangeTypeModify
+		default:
+			change.Type = ChangeTypeAdd
+		}
+		combined = append(combined, change)
+	}
+
+	sort.Slice(combined, func(i, j int) bool {
+		return combined[i].Key < combined[j].Key
+	})
+	return combined, nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmendCommit(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	now := time.Now()
+
+	pushed, err := svc.AddCommit("", "dev", "base", "alice", []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}, now)
+	require.NoError(t, err)
+	require.NoError(t, svc.UpdateRemoteHead("dev", pushed.ID))
+
+	typo, err := svc.AddCommit("", "dev", "add B", "alice", []Change{{Type: ChangeTypeAdd, Key: "B", Value: "tpyo"}}, now)
+	require.NoError(t, err)
+	_, err = svc.CreateTag("dev", "v1", typo.ID, false)
+	require.NoError(t, err)
+
+	amended, err := svc.AmendCommit("dev", "", "alice", []Change{{Type: ChangeTypeModify, Key: "B", Value: "typo"}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "add B", amended.Message, "The message should be kept when none is given")
+	assert.Equal(t, pushed.ID, amended.ParentID)
+	require.Len(t, amended.Changes, 1)
+	assert.Equal(t, "typo", amended.Changes[0].Value)
+
+	head, err := svc.GetHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, amended.ID, head.LocalHead)
+
+	tag, err := svc.GetTag("dev", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, amended.ID, tag.CommitID, "Tags should follow the amended commit")
+
+	history, err := svc.ListHistory("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{amended.ID, pushed.ID}, commitIDs(history), "The replaced commit should drop out of history")
+
+	require.NoError(t, svc.UpdateRemoteHead("dev", amended.ID))
+	_, err = svc.AmendCommit("dev", "reword", "alice", nil, now)
+	assert.ErrorIs(t, err, ErrRewritePushed)
+}
+
+func TestRewordAmendGetsNewID(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	now := time.Now()
+
+	original, err := svc.AddCommit("", "dev", "add B", "alice", []Change{{Type: ChangeTypeAdd, Key: "B", Value: "1"}}, now)
+	require.NoError(t, err)
+
+	reworded, err := svc.AmendCommit("dev", "add B for the new API", "alice", nil, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, original.ID, reworded.ID, "A reworded commit should not reuse the original ID")
+	assert.Equal(t, original.ID, reworded.Amends)
+
+	issues, err := svc.CheckHistory("dev")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	resolved, err := svc.ResolveCommit("dev", reworded.ID[:7])
+	require.NoError(t, err)
+	assert.Equal(t, "add B for the new API", resolved.Message)
+}
+
+func TestSquashCommits(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	now := time.Now()
+
+	base, err := svc.AddCommit("", "dev", "base", "alice", []Change{{Type: ChangeTypeAdd, Key: "A", Value: "1"}}, now)
+	require.NoError(t, err)
+	require.NoError(t, svc.UpdateRemoteHead("dev", base.ID))
+	first, err := svc.AddCommit("", "dev", "add B", "alice", []Change{{Type: ChangeTypeAdd, Key: "B", Value: "tpyo"}, {Type: ChangeTypeAdd, Key: "TMP", Value: "x"}, {Type: ChangeTypeModify, Key: "TMP", Value: "y"}}, now)
+	require.NoError(t, err)
+	second, err := svc.AddCommit("", "dev", "fix B", "alice", []Change{{Type: ChangeTypeModify, Key: "B", Value: "typo"}, {Type: ChangeTypeRemove, Key: "TMP"}}, now)
+	require.NoError(t, err)
+	third, err := svc.AddCommit("", "dev", "add C", "alice", []Change{{Type: ChangeTypeAdd, Key: "C", Value: "3"}}, now)
+	require.NoError(t, err)
+
+	before, err := svc.ComputeState("dev", third.ID)
+	require.NoError(t, err)
+
+	_, err = svc.SquashCommits("dev", base.ID, second.ID, "", "alice", now)
+	assert.ErrorIs(t, err, ErrRewritePushed, "Pushed commits must not be rewritten")
+	_, err = svc.SquashCommits("dev", second.ID, first.ID, "", "alice", now)
+	assert.Error(t, err, "The range must run from older to newer")
+
+	squashed, err := svc.SquashCommits("dev", first.ID, second.ID, "", "alice", now)
+	require.NoError(t, err)
+	assert.Equal(t, "add B; fix B", squashed.Message)
+	assert.Equal(t, base.ID, squashed.ParentID)
+	require.Len(t, squashed.Changes, 1, "Add-then-remove should cancel out")
+	assert.Equal(t, "typo", squashed.Changes[0].Value)
+	assert.Equal(t, ChangeTypeAdd, squashed.Changes[0].Type, "B did not exist before the squashed range")
+
+	history, err := svc.ListHistory("dev")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "add C", history[0].Message)
+	assert.Equal(t, squashed.ID, history[0].ParentID, "Later commits should be replayed on the squashed commit")
+
+	after, err := svc.ComputeState("dev", history[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "Squashing must not change the resulting state")
+}
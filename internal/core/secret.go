@@ -1,9 +1,9 @@
 package core
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
 
 	"github.com/jawahars16/jebi/internal/io"
 )
@@ -14,29 +14,29 @@ var (
 )
 
 type secretService struct {
-	workingDir string
+	store io.Store
 }
 
 func NewSecretService(workingDir string) *secretService {
-	return &secretService{
-		workingDir: workingDir,
-	}
+	return NewSecretServiceWithStore(ProjectStore(workingDir))
 }
 
-func (s *secretService) envDir(env string) string {
-	return filepath.Join(s.workingDir, fmt.Sprintf(".%s", AppName), EnvDirPath, env)
+// NewSecretServiceWithStore creates a secret service backed by store
+func NewSecretServiceWithStore(store io.Store) *secretService {
+	return &secretService{
+		store: store,
+	}
 }
 
 func (s *secretService) AddSecret(key, env string, secret Secret) error {
-	envDir := s.envDir(env)
-	secretPath := filepath.Join(envDir, SecretFileName)
+	secretPath := envPath(env, SecretFileName)
 
 	var data map[string]Secret
-	if _, err := os.Stat(secretPath); os.IsNotExist(err) {
+	if _, err := s.store.Stat(secretPath); errors.Is(err, fs.ErrNotExist) {
 		// If secret file does not exist, create an empty one
 		data = make(map[string]Secret)
 	} else {
-		data, err = io.ReadJSONFile[map[string]Secret](secretPath)
+		data, err = io.ReadJSON[map[string]Secret](s.store, secretPath)
 		if err != nil {
 			return fmt.Errorf("failed to read secrets: %w", err)
 		}
@@ -47,7 +47,7 @@ func (s *secretService) AddSecret(key, env string, secret Secret) error {
 
 	data[key] = secret
 
-	err := io.WriteJSONToFile(secretPath, data)
+	err := io.WriteJSON(s.store, secretPath, data)
 	if err != nil {
 		return fmt.Errorf("failed to write secrets: %w", err)
 	}
@@ -55,13 +55,15 @@ func (s *secretService) AddSecret(key, env string, secret Secret) error {
 }
 
 func (s *secretService) SetSecret(key, env string, secret Secret) (ChangeType, error) {
-	envDir := s.envDir(env)
-	secretPath := filepath.Join(envDir, SecretFileName)
+	secretPath := envPath(env, SecretFileName)
 
-	data, err := io.ReadJSONFile[map[string]Secret](secretPath)
+	data, err := io.ReadJSON[map[string]Secret](s.store, secretPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read secrets: %w", err)
 	}
+	if data == nil {
+		data = make(map[string]Secret)
+	}
 
 	var action ChangeType
 	_, exists := data[key]
@@ -72,7 +74,7 @@ func (s *secretService) SetSecret(key, env string, secret Secret) (ChangeType, e
 	}
 
 	data[key] = secret
-	err = io.WriteJSONToFile(secretPath, data)
+	err = io.WriteJSON(s.store, secretPath, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to write secrets: %w", err)
 	}
@@ -80,10 +82,9 @@ func (s *secretService) SetSecret(key, env string, secret Secret) (ChangeType, e
 }
 
 func (s *secretService) RemoveSecret(key, env string) error {
-	envDir := s.envDir(env)
-	secretPath := filepath.Join(envDir, SecretFileName)
+	secretPath := envPath(env, SecretFileName)
 
-	data, err := io.ReadJSONFile[map[string]Secret](secretPath)
+	data, err := io.ReadJSON[map[string]Secret](s.store, secretPath)
 	if err != nil {
 		return fmt.Errorf("failed to read secrets: %w", err)
 	}
@@ -94,7 +95,7 @@ func (s *secretService) RemoveSecret(key, env string) error {
 
 	delete(data, key)
 
-	err = io.WriteJSONToFile(secretPath, data)
+	err = io.WriteJSON(s.store, secretPath, data)
 	if err != nil {
 		return fmt.Errorf("failed to write secrets: %w", err)
 	}
@@ -102,15 +103,14 @@ func (s *secretService) RemoveSecret(key, env string) error {
 }
 
 func (s *secretService) ListSecrets(projectId, env string) ([]Secret, error) {
-	envDir := s.envDir(env)
-	secretPath := filepath.Join(envDir, SecretFileName)
+	secretPath := envPath(env, SecretFileName)
 
-	if _, err := os.Stat(secretPath); os.IsNotExist(err) {
+	if _, err := s.store.Stat(secretPath); errors.Is(err, fs.ErrNotExist) {
 		// If secret file does not exist, return empty slice
 		return []Secret{}, nil
 	}
 
-	data, err := io.ReadJSONFile[map[string]Secret](secretPath)
+	data, err := io.ReadJSON[map[string]Secret](s.store, secretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read secrets: %w", err)
 	}
@@ -130,3 +130,53 @@ func (s *secretService) ListSecrets(projectId, env string) ([]Secret, error) {
 	}
 	return secrets, nil
 }
+
+// ApplyChanges applies a set of changes to the secrets file of an environment
+func (s *secretService) ApplyChanges(env string, changes []Change) error {
+	secretPath := envPath(env, SecretFileName)
+
+	data, err := io.ReadJSON[map[string]Secret](s.store, secretPath)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets: %w", err)
+	}
+	if data == nil {
+		data = make(map[string]Secret)
+	}
+
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeTypeAdd, ChangeTypeModify:
+			data[change.Key] = Secret{
+				Value:    change.Value,
+				Nonce:    change.Nonce,
+				NoSecret: change.NoSecret,
+			}
+		case ChangeTypeRemove:
+			delete(data, change.Key)
+		}
+	}
+
+	if err := io.WriteJSON(s.store, secretPath, data); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
+
+// ReplaceSecrets overwrites the secrets file of an environment with the given state
+func (s *secretService) ReplaceSecrets(env string, state map[string]Secret) error {
+	secretPath := envPath(env, SecretFileName)
+
+	data := make(map[string]Secret, len(state))
+	for key, secret := range state {
+		data[key] = Secret{
+			Value:    secret.Value,
+			Nonce:    secret.Nonce,
+			NoSecret: secret.NoSecret,
+		}
+	}
+
+	if err := io.WriteJSON(s.store, secretPath, data); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/keystore"
+)
+
+const signingKeyName = "signing_key"
+
+// CommitSigner signs commit payloads on behalf of the current user
+type CommitSigner interface {
+	Sign(payload []byte) (signature, publicKey string, err error)
+}
+
+// SignatureStatus describes the outcome of verifying a commit signature
+type SignatureStatus string
+
+const (
+	SignatureVerified  SignatureStatus = "verified"
+	SignatureUntrusted SignatureStatus = "untrusted"
+	SignatureInvalid   SignatureStatus = "invalid"
+	SignatureMissing   SignatureStatus = "unsigned"
+)
+
+type signingService struct {
+	keystore keystore.KeyStore
+}
+
+func NewSigningService(workingDir string) *signingService {
+	return &signingService{
+		keystore: keystore.NewDefault(workingDir),
+	}
+}
+
+func NewSigningServiceWithKeystore(ks keystore.KeyStore) *signingService {
+	return &signingService{
+		keystore: ks,
+	}
+}
+
+// Sign signs the payload with the user's Ed25519 key, generating the key on first use
+func (s *signingService) Sign(payload []byte) (string, string, error) {
+	privateKey, err := s.loadOrCreateKey()
+	if err != nil {
+		return "", "", err
+	}
+	signature := ed25519.Sign(privateKey, payload)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(signature), base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+// PublicKey returns the user's base64-encoded public signing key
+func (s *signingService) PublicKey() (string, error) {
+	privateKey, err := s.loadOrCreateKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)), nil
+}
+
+func (s *signingService) loadOrCreateKey() (ed25519.PrivateKey, error) {
+	var encodedSeed string
+	err := s.keystore.Get(signingKeyName, &encodedSeed)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("stored signing key is corrupted")
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	// Any other failure may hide an existing key, which must never be replaced
+	if !errors.Is(err, keystore.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := s.keystore.Set(signingKeyName, base64.StdEncoding.EncodeToString(privateKey.Seed())); err != nil {
+		return nil, fmt.Errorf("failed to save signing key: %w", err)
+	}
+	return privateKey, nil
+}
+
+// CommitSigningPayload returns the canonical bytes covered by a commit signature
+func CommitSigningPayload(commit Commit) []byte {
+	payload := struct {
+		ID        string          `json:"id"`
+		ParentID  string          `json:"parentId"`
+		Merge     string          `json:"mergeParentId,omitempty"`
+		Amends    string          `json:"amends,omitempty"`
+		Message   string          `json:"message"`
+		Author    string          `json:"author"`
+		Committer string          `json:"committer,omitempty"`
+		Timestamp string          `json:"timestamp"`
+		Changes   json.RawMessage `json:"changes"`
+	}{
+		ID:        commit.ID,
+		ParentID:  commit.ParentID,
+		Merge:     commit.MergeParentID,
+		Amends:    commit.Amends,
+		Message:   commit.Message,
+		Author:    commit.Author,
+		Committer: commit.Committer,
+		Timestamp: commit.Timestamp.UTC().Format(time.RFC3339Nano),
+		Changes:   canonicalChanges(commit.Changes),
+	}
+
+	// Marshalling plain strings and pre-encoded JSON cannot fail
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+// VerifyCommitSignature checks a commit signature against the project's trusted keys
+func VerifyCommitSignature(commit Commit, trustedKeys []TrustedKey) (SignatureStatus, *TrustedKey) {
+	if commit.Signature == "" || commit.SignerKey == "" {
+		return SignatureMissing, nil
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(commit.SignerKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return SignatureInvalid, nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(commit.Signature)
+	if err != nil {
+		return SignatureInvalid, nil
+	}
+	if !ed25519.Verify(publicKey, CommitSigningPayload(commit), signature) {
+		return SignatureInvalid, nil
+	}
+
+	for _, trusted := range trustedKeys {
+		if trusted.PublicKey == commit.SignerKey {
+			return SignatureVerified, &trusted
+		}
+	}
+	return SignatureUntrusted, nil
+}
+
+// KeyFingerprint returns a short, human-comparable fingerprint for a public key
+func KeyFingerprint(publicKey string) string {
+	hash := sha256.Sum256([]byte(publicKey))
+	return fmt.Sprintf("%x", hash)[:16]
+}
+
+// ValidatePublicKey checks that a string is a base64-encoded Ed25519 public key
+func ValidatePublicKey(publicKey string) error {
+	decoded, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(decoded) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key length: expected %d bytes, got %d", ed25519.PublicKeySize, len(decoded))
+	}
+	return nil
+}
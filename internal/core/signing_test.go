@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/keystore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedCommitVerification(t *testing.T) {
+	signer := NewSigningServiceWithKeystore(keystore.NewDiskOnly(t.TempDir()))
+	svc := newTestCommitService(t, "dev")
+	svc.signer = signer
+
+	commit, err := svc.AddCommit("", "dev", "rotate key", "alice@example.com",
+		[]Change{{Type: ChangeTypeAdd, Key: "API_KEY", Value: "cipher", Nonce: "nonce"}}, time.Now())
+	require.NoError(t, err)
+	require.NotEmpty(t, commit.Signature, "New commits should be signed")
+
+	publicKey, err := signer.PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, commit.SignerKey, "Signer key should be the user's public key")
+
+	// Round-trip through disk to make sure the stored form still verifies
+	stored, err := svc.GetCommit("dev", commit.ID)
+	require.NoError(t, err)
+
+	status, _ := VerifyCommitSignature(*stored, nil)
+	assert.Equal(t, SignatureUntrusted, status, "Valid signatures from unknown keys are untrusted")
+
+	status, trusted := VerifyCommitSignature(*stored, []TrustedKey{{Name: "alice", PublicKey: publicKey}})
+	assert.Equal(t, SignatureVerified, status)
+	assert.Equal(t, "alice", trusted.Name)
+
+	stored.Author = "mallory@example.com"
+	status, _ = VerifyCommitSignature(*stored, []TrustedKey{{Name: "alice", PublicKey: publicKey}})
+	assert.Equal(t, SignatureInvalid, status, "Tampered commits should fail verification")
+
+	status, _ = VerifyCommitSignature(Commit{ID: "legacy"}, nil)
+	assert.Equal(t, SignatureMissing, status)
+}
+
+// failingKeystore fails every read as an unavailable keyring would
+type failingKeystore struct {
+	keystore.KeyStore
+	sets int
+}
+
+func (k *failingKeystore) Get(key string, target interface{}) error {
+	return fmt.Errorf("keyring is locked")
+}
+
+func (k *failingKeystore) Set(key string, value interface{}) error {
+	k.sets++
+	return nil
+}
+
+func TestSigningKeyIsNotReplacedOnReadFailure(t *testing.T) {
+	ks := &failingKeystore{}
+	signer := NewSigningServiceWithKeystore(ks)
+
+	_, _, err := signer.Sign([]byte("payload"))
+	assert.ErrorContains(t, err, "keyring is locked")
+	assert.Zero(t, ks.sets, "A key that cannot be read must not be overwritten")
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+var (
+	ErrStashEmpty        = fmt.Errorf("no stash entries")
+	ErrStashEntryMissing = fmt.Errorf("stash entry does not exist")
+)
+
+// StashEntry is a set of uncommitted changes shelved away from an environment
+type StashEntry struct {
+	Env        string    `json:"env"`
+	BaseCommit string    `json:"baseCommit,omitempty"` // Local HEAD when the changes were stashed
+	Message    string    `json:"message"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Changes    []Change  `json:"changes"` // Encrypted values and nonces exactly as they were staged
+}
+
+type stashService struct {
+	store io.Store
+}
+
+func NewStashService(workingDir string) *stashService {
+	return NewStashServiceWithStore(ProjectStore(workingDir))
+}
+
+// NewStashServiceWithStore creates a stash service backed by store
+func NewStashServiceWithStore(store io.Store) *stashService {
+	return &stashService{
+		store: store,
+	}
+}
+
+// Push puts an entry on top of the stash stack
+func (s *stashService) Push(entry StashEntry) error {
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	return s.save(append([]StashEntry{entry}, entries...))
+}
+
+// List returns all stash entries, newest first
+func (s *stashService) List() ([]StashEntry, error) {
+	entries, err := io.ReadJSON[[]StashEntry](s.store, StashFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stash: %w", err)
+	}
+	return entries, nil
+}
+
+// Get returns the stash entry at the given index (0 is the newest)
+func (s *stashService) Get(index int) (*StashEntry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrStashEmpty
+	}
+	if index < 0 || index >= len(entries) {
+		return nil, fmt.Errorf("%w: stash@{%d}", ErrStashEntryMissing, index)
+	}
+	return &entries[index], nil
+}
+
+// Drop removes the stash entry at the given index
+func (s *stashService) Drop(index int) error {
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return ErrStashEmpty
+	}
+	if index < 0 || index >= len(entries) {
+		return fmt.Errorf("%w: stash@{%d}", ErrStashEntryMissing, index)
+	}
+	return s.save(append(entries[:index], entries[index+1:]...))
+}
+
+// RenameEnv moves stash entries of a renamed environment to its new name
+func (s *stashService) RenameEnv(oldName, newName string) error {
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for i := range entries {
+		if entries[i].Env == oldName {
+			entries[i].Env = newName
+		}
+	}
+	return s.save(entries)
+}
+
+func (s *stashService) save(entries []StashEntry) error {
+	if entries == nil {
+		entries = []StashEntry{}
+	}
+	if err := io.WriteJSON(s.store, StashFileName, entries); err != nil {
+		return fmt.Errorf("failed to write stash: %w", err)
+	}
+	return nil
+}
+
+// StashConflicts returns the stashed keys that cannot be re-applied cleanly:
+// keys changed by commits made after the stash (baseState vs headState) and
+// keys that have new pending changes of their own
+func StashConflicts(entry StashEntry, baseState, headState map[string]Secret, pending []Change) []string {
+	pendingKeys := make(map[string]bool, len(pending))
+	for _, change := range pending {
+		pendingKeys[change.Key] = true
+	}
+
+	var conflicts []string
+	for _, change := range entry.Changes {
+		base, inBase := baseState[change.Key]
+		head, inHead := headState[change.Key]
+
+		changedSinceStash := inBase != inHead || (inBase && !sameSecretValue(base, head))
+		if changedSinceStash || pendingKeys[change.Key] {
+			conflicts = append(conflicts, change.Key)
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts
+}
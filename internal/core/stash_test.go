@@ -0,0 +1,66 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStashStack(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "."+AppName), 0755))
+	s := NewStashService(dir)
+
+	_, err := s.Get(0)
+	assert.ErrorIs(t, err, ErrStashEmpty)
+
+	require.NoError(t, s.Push(StashEntry{Env: "dev", Message: "first"}))
+	require.NoError(t, s.Push(StashEntry{Env: "dev", Message: "second"}))
+
+	entries, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "second", entries[0].Message)
+
+	_, err = s.Get(2)
+	assert.ErrorIs(t, err, ErrStashEntryMissing)
+
+	require.NoError(t, s.Drop(0))
+	entry, err := s.Get(0)
+	require.NoError(t, err)
+	assert.Equal(t, "first", entry.Message)
+
+	require.NoError(t, s.Drop(0))
+	entries, err = s.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStashConflicts(t *testing.T) {
+	entry := StashEntry{
+		Env: "dev",
+		Changes: []Change{
+			{Type: ChangeTypeModify, Key: "A", Value: "a2"},
+			{Type: ChangeTypeModify, Key: "B", Value: "b2"},
+			{Type: ChangeTypeAdd, Key: "C", Value: "c1"},
+			{Type: ChangeTypeRemove, Key: "D"},
+		},
+	}
+	base := map[string]Secret{
+		"A": {Key: "A", Value: "a1"},
+		"B": {Key: "B", Value: "b1"},
+		"D": {Key: "D", Value: "d1"},
+	}
+	head := map[string]Secret{
+		"A": {Key: "A", Value: "a1"},
+		"B": {Key: "B", Value: "b-hotfix"},
+		"D": {Key: "D", Value: "d1"},
+	}
+	pending := []Change{{Type: ChangeTypeAdd, Key: "C", Value: "c-other"}}
+
+	assert.Equal(t, []string{"B", "C"}, StashConflicts(entry, base, head, pending))
+	assert.Empty(t, StashConflicts(entry, base, base, nil))
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+// ProjectStore returns the store holding the project in workingDir
+func ProjectStore(workingDir string) io.Store {
+	return io.NewFileStore(filepath.Join(workingDir, fmt.Sprintf(".%s", AppName)))
+}
+
+// envPath returns the store name of an environment's directory, or of a file in it
+func envPath(env string, elem ...string) string {
+	return path.Join(append([]string{EnvDirPath, env}, elem...)...)
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServicesOnMemoryStore(t *testing.T) {
+	store := io.NewMemoryStore()
+	envSvc := NewEnvServiceWithStore(store)
+	secretSvc := NewSecretServiceWithStore(store)
+	changeSvc := NewChangeRecordServiceWithStore(store)
+	commitSvc := NewCommitServiceWithStore(store, nil)
+	projectSvc := NewProjectServiceWithStore(store)
+
+	_, err := projectSvc.SaveProjectConfig("p1", "demo", "", "dev")
+	require.NoError(t, err)
+	require.NoError(t, envSvc.CreateEnv("dev"))
+	require.NoError(t, envSvc.SetCurrentEnv("dev"))
+
+	_, err = secretSvc.SetSecret("API_KEY", "dev", Secret{Value: "v1"})
+	require.NoError(t, err)
+	require.NoError(t, changeSvc.AddChangeRecord("dev", string(ChangeTypeAdd), "API_KEY", "v1", "", false))
+
+	pending, err := changeSvc.GetPendingChanges("dev")
+	require.NoError(t, err)
+	commit, err := commitSvc.AddCommit("", "dev", "first", "me", pending, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, changeSvc.ClearPendingChanges("dev"))
+
+	require.NoError(t, envSvc.RenameEnv("dev", "prod"))
+	current, err := envSvc.CurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current)
+
+	state, err := commitSvc.ComputeState("prod", commit.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", state["API_KEY"].Value)
+	secrets, err := secretSvc.ListSecrets("p1", "prod")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+
+	project, err := projectSvc.LoadProjectConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "demo", project.Name)
+
+	require.NoError(t, envSvc.RemoveEnv("prod"))
+	envs, err := envSvc.ListEnvs()
+	require.NoError(t, err)
+	assert.Empty(t, envs)
+	trash, err := store.ReadDir(TrashDirPath)
+	require.NoError(t, err)
+	assert.Len(t, trash, 1, "Removed environments should be kept in the trash")
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/io"
+)
+
+var (
+	ErrTagNotFound    = fmt.Errorf("tag not found")
+	ErrTagExists      = fmt.Errorf("tag already exists")
+	ErrInvalidTagName = fmt.Errorf("invalid tag name")
+)
+
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// ValidateTagName checks that a tag name is safe to use as a commit reference
+func ValidateTagName(name string) error {
+	if !tagNamePattern.MatchString(name) || len(name) > 128 {
+		return fmt.Errorf("%w '%s': use letters, digits, '.', '_', '-' or '/'", ErrInvalidTagName, name)
+	}
+	return nil
+}
+
+func (s *commitService) getTagsPath(env string) string {
+	return envPath(env, TagsFileName)
+}
+
+// ListTags returns the tags of an environment sorted by name
+func (s *commitService) ListTags(env string) ([]Tag, error) {
+	tags, err := io.ReadJSON[[]Tag](s.store, s.getTagsPath(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// GetTag retrieves a tag by name
+func (s *commitService) GetTag(env, name string) (*Tag, error) {
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		if tag.Name == name {
+			return &tag, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in environment %s", ErrTagNotFound, name, env)
+}
+
+// CreateTag points a new tag at the commit referenced by ref (HEAD when empty).
+// An existing tag is only moved when force is set.
+func (s *commitService) CreateTag(env, name, ref string, force bool) (*Tag, error) {
+	if err := ValidateTagName(name); err != nil {
+		return nil, err
+	}
+
+	if ref == "" {
+		head, err := s.GetHead(env)
+		if err != nil {
+			return nil, err
+		}
+		if head.LocalHead == "" {
+			return nil, fmt.Errorf("%w: no commits in environment %s", ErrCommitNotFound, env)
+		}
+		ref = head.LocalHead
+	}
+
+	commit, err := s.ResolveCommit(env, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := Tag{Name: name, CommitID: commit.ID, CreatedAt: time.Now()}
+	replaced := false
+	for i := range tags {
+		if tags[i].Name != name {
+			continue
+		}
+		if !force {
+			return nil, fmt.Errorf("%w: %s points to %s", ErrTagExists, name, tags[i].CommitID)
+		}
+		tags[i] = tag
+		replaced = true
+	}
+	if !replaced {
+		tags = append(tags, tag)
+	}
+
+	if err := s.saveTags(env, tags); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// DeleteTag removes a tag; the commit it points to is left untouched. A tag the
+// remote has stays in its record until the deletion is pushed (see DeletedTags).
+func (s *commitService) DeleteTag(env, name string) error {
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return err
+	}
+
+	for i, tag := range tags {
+		if tag.Name == name {
+			return s.saveTags(env, append(tags[:i], tags[i+1:]...))
+		}
+	}
+	return fmt.Errorf("%w: %s in environment %s", ErrTagNotFound, name, env)
+}
+
+// UnpushedTags returns the tags the remote does not have yet, or has pointing
+// to another commit
+func (s *commitService) UnpushedTags(env string) ([]Tag, error) {
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+	remoteTags, err := s.remoteTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var unpushed []Tag
+	for _, tag := range tags {
+		if remoteTags[tag.Name] != tag.CommitID {
+			unpushed = append(unpushed, tag)
+		}
+	}
+	return unpushed, nil
+}
+
+// DeletedTags returns the names of tags the remote has but that were deleted
+// locally, so push can delete them there too
+func (s *commitService) DeletedTags(env string) ([]string, error) {
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+	remoteTags, err := s.remoteTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	local := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		local[tag.Name] = true
+	}
+	var deleted []string
+	for name := range remoteTags {
+		if !local[name] {
+			deleted = append(deleted, name)
+		}
+	}
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+// MarkTagsDeleted records that the remote no longer has the named tags
+func (s *commitService) MarkTagsDeleted(env string, names []string) error {
+	remoteTags, err := s.remoteTags(env)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		delete(remoteTags, name)
+	}
+	if err := io.WriteJSON(s.store, envPath(env, RemoteTagsFileName), remoteTags); err != nil {
+		return fmt.Errorf("failed to write remote tags: %w", err)
+	}
+	return nil
+}
+
+// MarkTagsPushed records that the remote has the given tags
+func (s *commitService) MarkTagsPushed(env string, tags []Tag) error {
+	remoteTags, err := s.remoteTags(env)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		remoteTags[tag.Name] = tag.CommitID
+	}
+	if err := io.WriteJSON(s.store, envPath(env, RemoteTagsFileName), remoteTags); err != nil {
+		return fmt.Errorf("failed to write remote tags: %w", err)
+	}
+	return nil
+}
+
+// remoteTags maps the names of tags the remote has to their commit IDs
+func (s *commitService) remoteTags(env string) (map[string]string, error) {
+	remoteTags, err := io.ReadJSON[map[string]string](s.store, envPath(env, RemoteTagsFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote tags: %w", err)
+	}
+	if remoteTags == nil {
+		remoteTags = make(map[string]string)
+	}
+	return remoteTags, nil
+}
+
+// ImportTags stores tags received from the remote, replacing local tags with the same name.
+// Tags pointing to commits that do not exist locally are skipped, as are tags
+// deleted locally whose deletion has not been pushed yet.
+func (s *commitService) ImportTags(env string, imported []Tag) error {
+	received, err := s.mergeTags(env, imported)
+	if err != nil {
+		return err
+	}
+	return s.MarkTagsPushed(env, received)
+}
+
+// ImportLocalTags stores tags that did not come from the remote (such as a
+// bundle's) like ImportTags, but leaves them unpushed
+func (s *commitService) ImportLocalTags(env string, imported []Tag) error {
+	_, err := s.mergeTags(env, imported)
+	return err
+}
+
+// mergeTags saves imported tags over the local ones and returns those it kept
+func (s *commitService) mergeTags(env string, imported []Tag) ([]Tag, error) {
+	tags, err := s.ListTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteTags, err := s.remoteTags(env)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Tag, len(tags))
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+	var received []Tag
+	for _, tag := range imported {
+		if ValidateTagName(tag.Name) != nil {
+			continue
+		}
+		// Still recorded as pushed but gone locally: deleted, and not to be restored
+		if _, exists := byName[tag.Name]; !exists && remoteTags[tag.Name] == tag.CommitID {
+			continue
+		}
+		if _, err := s.GetCommit(env, tag.CommitID); err != nil {
+			continue
+		}
+		byName[tag.Name] = tag
+		received = append(received, tag)
+	}
+
+	merged := make([]Tag, 0, len(byName))
+	for _, tag := range byName {
+		merged = append(merged, tag)
+	}
+	if err := s.saveTags(env, merged); err != nil {
+		return nil, err
+	}
+	return received, nil
+}
+
+func (s *commitService) saveTags(env string, tags []Tag) error {
+	if tags == nil {
+		tags = []Tag{}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	if err := io.WriteJSON(s.store, s.getTagsPath(env), tags); err != nil {
+		return fmt.Errorf("failed to write tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "abc123456789", Message: "first"},
+		{ID: "abd987654321", Message: "second", ParentID: "abc123456789"},
+	}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "abd987654321"))
+
+	tag, err := svc.CreateTag("dev", "v1.0", "abc", false)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123456789", tag.CommitID, "Tags should accept commit prefixes")
+
+	tag, err = svc.CreateTag("dev", "latest", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "abd987654321", tag.CommitID, "Tags should default to HEAD")
+
+	commit, err := svc.ResolveCommit("dev", "v1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "first", commit.Message, "Tags should resolve like commit IDs")
+
+	_, err = svc.CreateTag("dev", "v1.0", "abd", false)
+	assert.ErrorIs(t, err, ErrTagExists)
+	_, err = svc.CreateTag("dev", "v1.0", "abd", true)
+	require.NoError(t, err, "Force should move an existing tag")
+
+	_, err = svc.CreateTag("dev", "-bad name", "", false)
+	assert.ErrorIs(t, err, ErrInvalidTagName)
+
+	require.NoError(t, svc.ImportTags("dev", []Tag{
+		{Name: "remote", CommitID: "abc123456789"},
+		{Name: "orphan", CommitID: "ffffffffffff"},
+	}))
+	tags, err := svc.ListTags("dev")
+	require.NoError(t, err)
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	assert.Equal(t, []string{"latest", "remote", "v1.0"}, names, "Tags to unknown commits should be skipped")
+
+	require.NoError(t, svc.DeleteTag("dev", "latest"))
+	assert.ErrorIs(t, svc.DeleteTag("dev", "latest"), ErrTagNotFound)
+	_, err = svc.ResolveCommit("dev", "latest")
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+}
+
+func TestUnpushedTags(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{
+		{ID: "abc123456789", Message: "first"},
+		{ID: "abd987654321", Message: "second", ParentID: "abc123456789"},
+	}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "abd987654321"))
+
+	require.NoError(t, svc.ImportTags("dev", []Tag{{Name: "remote", CommitID: "abc123456789"}}))
+	_, err := svc.CreateTag("dev", "v1.0", "abc", false)
+	require.NoError(t, err)
+
+	unpushed, err := svc.UnpushedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, tagNames(unpushed), "Tags received from the remote should not be pushed back")
+
+	require.NoError(t, svc.MarkTagsPushed("dev", unpushed))
+	unpushed, err = svc.UnpushedTags("dev")
+	require.NoError(t, err)
+	assert.Empty(t, unpushed)
+
+	_, err = svc.CreateTag("dev", "v1.0", "abd", true)
+	require.NoError(t, err)
+	unpushed, err = svc.UnpushedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, tagNames(unpushed), "A moved tag should be pushed again")
+}
+
+func TestDeletedTags(t *testing.T) {
+	svc := newTestCommitService(t, "dev")
+	require.NoError(t, svc.saveCommits("dev", []Commit{{ID: "abc123456789"}, {ID: "abd987654321", ParentID: "abc123456789"}}))
+	require.NoError(t, svc.UpdateLocalHead("dev", "abd987654321"))
+	require.NoError(t, svc.ImportTags("dev", []Tag{{Name: "v1.0", CommitID: "abc123456789"}}))
+	_, err := svc.CreateTag("dev", "local", "abc", false)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteTag("dev", "v1.0"))
+	require.NoError(t, svc.DeleteTag("dev", "local"))
+	deleted, err := svc.DeletedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, deleted, "Only tags the remote has need their deletion pushed")
+
+	require.NoError(t, svc.ImportTags("dev", []Tag{{Name: "v1.0", CommitID: "abc123456789"}}))
+	tags, err := svc.ListTags("dev")
+	require.NoError(t, err)
+	assert.Empty(t, tags, "A deletion that was not pushed yet should survive a pull")
+
+	require.NoError(t, svc.MarkTagsDeleted("dev", deleted))
+	deleted, err = svc.DeletedTags("dev")
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	require.NoError(t, svc.ImportTags("dev", []Tag{{Name: "v1.0", CommitID: "abd987654321"}}))
+	tags, err = svc.ListTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, tagNames(tags), "A tag created again on the remote should be imported")
+}
+
+func tagNames(tags []Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -2,6 +2,8 @@ package crypt
 
 import (
 	"crypto/rand"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +29,37 @@ func Test_EncryptDecrypt(t *testing.T) {
 		t.Fatalf("decrypted value mismatch: got %q, want %q", decrypted, plaintext)
 	}
 }
+
+func Test_SealOpenWithPassphrase(t *testing.T) {
+	cryptService := NewService("/tmp") // workingDir is not used in this test
+	plaintext := []byte(`{"project":"demo"}`)
+
+	sealed, err := cryptService.SealWithPassphrase("correct horse", plaintext)
+	if err != nil {
+		t.Fatalf("SealWithPassphrase failed: %v", err)
+	}
+	if strings.Contains(string(sealed), "demo") {
+		t.Fatalf("sealed data contains the plaintext")
+	}
+
+	opened, err := cryptService.OpenWithPassphrase("correct horse", sealed)
+	if err != nil {
+		t.Fatalf("OpenWithPassphrase failed: %v", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("opened data mismatch: got %q, want %q", opened, plaintext)
+	}
+
+	if _, err := cryptService.OpenWithPassphrase("wrong horse", sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase for a wrong passphrase, got %v", err)
+	}
+
+	// The header is authenticated: weakening the KDF must be detected
+	tampered := strings.Replace(string(sealed), `"time": 3`, `"time": 1`, 1)
+	if tampered == string(sealed) {
+		t.Fatalf("test did not tamper with the header")
+	}
+	if _, err := cryptService.OpenWithPassphrase("correct horse", []byte(tampered)); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase for a tampered header, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
 )
 
 // GenerateKey creates a 32-byte random AES key and returns it in base64 form.
@@ -27,7 +28,7 @@ func (s *cryptService) SaveKey(encodedKey, project string) error {
 		if err := os.MkdirAll(filepath.Dir(s.keyFilePath), 0700); err != nil {
 			return fmt.Errorf("failed to create key directory: %w", err)
 		}
-		if err := os.WriteFile(s.keyFilePath, []byte(encodedKey), 0600); err != nil {
+		if err := io.WriteFileAtomic(s.keyFilePath, []byte(encodedKey), 0600); err != nil {
 			return fmt.Errorf("failed to save key to both keystore and file: keystore error: %v, file error: %w", err, err)
 		}
 	}
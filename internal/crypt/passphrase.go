@@ -0,0 +1,139 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrWrongPassphrase = fmt.Errorf("wrong passphrase or corrupted data")
+
+// maxArgonMemory bounds the KDF memory an untrusted envelope may ask for
+const maxArgonMemory = 1024 * 1024 // 1 GB
+
+// passphraseHeader describes how an envelope was sealed. It is authenticated
+// together with the ciphertext, so its parameters cannot be altered either.
+type passphraseHeader struct {
+	KDF     string `json:"kdf"`
+	Cipher  string `json:"cipher"`
+	Salt    string `json:"salt"`
+	Nonce   string `json:"nonce"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// passphraseEnvelope is data encrypted with a key derived from a passphrase
+type passphraseEnvelope struct {
+	Header     passphraseHeader `json:"header"`
+	Ciphertext string           `json:"ciphertext"`
+}
+
+// SealWithPassphrase encrypts and authenticates data with an AES-GCM key
+// derived from the passphrase with Argon2id
+func (s *cryptService) SealWithPassphrase(passphrase string, plaintext []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	salt := make([]byte, core.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	nonce := make([]byte, core.NonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := passphraseHeader{
+		KDF:     core.KdfAlgo,
+		Cipher:  core.CipherAlgo,
+		Salt:    base64.StdEncoding.EncodeToString(salt),
+		Nonce:   base64.StdEncoding.EncodeToString(nonce),
+		Time:    core.ArgonTime,
+		Memory:  core.ArgonMemory,
+		Threads: core.ArgonThreads,
+	}
+	aesgcm, additionalData, err := passphraseCipher(passphrase, header, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := passphraseEnvelope{
+		Header:     header,
+		Ciphertext: base64.StdEncoding.EncodeToString(aesgcm.Seal(nil, nonce, plaintext, additionalData)),
+	}
+	sealed, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode envelope: %w", err)
+	}
+	return sealed, nil
+}
+
+// OpenWithPassphrase decrypts data sealed by SealWithPassphrase. It fails with
+// ErrWrongPassphrase when the passphrase is wrong or the data was modified.
+func (s *cryptService) OpenWithPassphrase(passphrase string, sealed []byte) ([]byte, error) {
+	var envelope passphraseEnvelope
+	if err := json.Unmarshal(sealed, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+
+	header := envelope.Header
+	if header.KDF != core.KdfAlgo || header.Cipher != core.CipherAlgo {
+		return nil, fmt.Errorf("unsupported envelope: kdf %q, cipher %q", header.KDF, header.Cipher)
+	}
+	if header.Time == 0 || header.Threads == 0 || header.Memory == 0 || header.Memory > maxArgonMemory {
+		return nil, fmt.Errorf("unsupported envelope: key derivation parameters out of range")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(header.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt encoding: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(header.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce encoding: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext encoding: %w", err)
+	}
+
+	aesgcm, additionalData, err := passphraseCipher(passphrase, header, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aesgcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	plaintext, err := aesgcm.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// passphraseCipher derives the AES-GCM cipher for an envelope and the
+// additional data binding its header to the ciphertext
+func passphraseCipher(passphrase string, header passphraseHeader, salt []byte) (cipher.AEAD, []byte, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, header.Time, header.Memory, header.Threads, core.KeyLen)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid AES key: %w", err)
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	// Marshalling a plain struct cannot fail
+	additionalData, _ := json.Marshal(header)
+	return aesgcm, additionalData, nil
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+const blameTimeFormat = "2006-01-02 15:04"
+
+type Blame struct {
+	envService          envService
+	commitService       commitService
+	changeRecordService changeRecordService
+	slate               slate
+}
+
+func NewBlameHandler(envService envService, commitService commitService, changeRecordService changeRecordService, slate slate) *Blame {
+	return &Blame{
+		envService:          envService,
+		commitService:       commitService,
+		changeRecordService: changeRecordService,
+		slate:               slate,
+	}
+}
+
+// Handle lists, for every committed key, the commit that last changed it
+func (h *Blame) Handle(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	entries, err := h.commitService.BlameHistory(env, head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to build commit chain: %w", err)
+	}
+
+	if len(entries) == 0 {
+		h.slate.WriteStyledText("No committed secrets in this environment", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	pending, err := h.changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		return fmt.Errorf("failed to get pending changes: %w", err)
+	}
+	uncommitted := make(map[string]bool, len(pending))
+	for _, change := range pending {
+		uncommitted[change.Key] = true
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("Blame - Environment: %s", env), ui.StyleOptions{
+		Color:  "82", // Light green
+		Bold:   true,
+		Margin: []int{0, 0, 1, 0}, // Bottom margin
+	})
+
+	width := 0
+	for _, entry := range entries {
+		width = max(width, len(entry.Change.Key))
+	}
+
+	for _, entry := range entries {
+		line := fmt.Sprintf("%-*s  %s  v%-3d %s  %s",
+			width, entry.Change.Key, entry.Commit.ID, entry.Version,
+			entry.Commit.Timestamp.Format(blameTimeFormat), entry.Commit.Author)
+		color := lipgloss.Color("15") // White
+		if uncommitted[entry.Change.Key] {
+			line += "  (uncommitted changes)"
+			color = lipgloss.Color("214") // Orange
+		}
+		h.slate.WriteIndentedText(line, ui.StyleOptions{
+			Color: color,
+		})
+	}
+	return nil
+}
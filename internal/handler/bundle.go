@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/jawahars16/jebi/internal/remote"
+	"github.com/urfave/cli/v3"
+)
+
+// bundlePassphraseEnv lets scripts provide the bundle passphrase without a prompt
+const bundlePassphraseEnv = "JEBI_BUNDLE_PASSPHRASE"
+
+type Bundle struct {
+	projectService projectService
+	envService     envService
+	commitService  commitService
+	cryptService   cryptService
+	appService     appService
+	clone          *Clone
+	pull           *Pull
+	slate          slate
+}
+
+func NewBundleHandler(projectService projectService, envService envService, commitService commitService, cryptService cryptService, appService appService, clone *Clone, pull *Pull, slate slate) *Bundle {
+	return &Bundle{
+		projectService: projectService,
+		envService:     envService,
+		commitService:  commitService,
+		cryptService:   cryptService,
+		appService:     appService,
+		clone:          clone,
+		pull:           pull,
+		slate:          slate,
+	}
+}
+
+// HandleCreate writes the history of an environment to a passphrase-encrypted
+// bundle file
+func (h *Bundle) HandleCreate(ctx context.Context, cmd *cli.Command) error {
+	env := cmd.String("env")
+	if env == "" {
+		current, err := h.envService.CurrentEnv()
+		if err != nil {
+			return fmt.Errorf("failed to get current environment: %w", err)
+		}
+		env = current
+	}
+	exists, err := h.envService.EnvExists(env)
+	if err != nil {
+		return fmt.Errorf("failed to check environment: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("environment '%s' does not exist", env)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load project: %w", err)
+	}
+	encodedKey, err := h.cryptService.LoadKeyWithoutDecoding(project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+	project.Key = encodedKey
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead == "" {
+		return fmt.Errorf("'%s' has no commits to bundle", env)
+	}
+
+	var base string
+	if since := cmd.String("since"); since != "" {
+		commit, err := h.commitService.ResolveCommit(env, since)
+		if err != nil {
+			return err
+		}
+		base = commit.ID
+	}
+	commits, err := h.commitService.CommitsSince(env, base)
+	if err != nil {
+		return fmt.Errorf("failed to collect commits: %w", err)
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("'%s' has no commits after %s", env, base)
+	}
+
+	tags, err := h.commitService.ListTags(env)
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	bundle := core.Bundle{
+		Format:    core.BundleFormat,
+		Version:   core.BundleVersion,
+		CreatedAt: time.Now(),
+		Project:   *project,
+		Environment: core.Environment{
+			Name:      env,
+			ProjectID: project.ID,
+		},
+		BaseCommit: base,
+		Head:       head.LocalHead,
+		Commits:    commits,
+		Tags:       tags,
+	}
+
+	// A new checkout needs the state to start from; a fast-forward computes it
+	if base == "" {
+		state, err := h.commitService.ComputeState(env, head.LocalHead)
+		if err != nil {
+			return fmt.Errorf("failed to compute state: %w", err)
+		}
+		for _, secret := range state {
+			secret.EnvironmentName = env
+			secret.ProjectId = project.ID
+			bundle.Secrets = append(bundle.Secrets, secret)
+		}
+		sort.Slice(bundle.Secrets, func(i, j int) bool {
+			return bundle.Secrets[i].Key < bundle.Secrets[j].Key
+		})
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	passphrase, err := h.passphrase(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := h.cryptService.SealWithPassphrase(passphrase, data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bundle: %w", err)
+	}
+
+	output := cmd.String("output")
+	if err := io.WriteFileAtomic(output, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if base == "" {
+		h.slate.ShowSuccess(fmt.Sprintf("Bundled the whole history of '%s' (%d commit(s)) into %s", env, len(commits), output))
+	} else {
+		h.slate.ShowSuccess(fmt.Sprintf("Bundled %d commit(s) of '%s' after %s into %s", len(commits), env, base, output))
+	}
+	return nil
+}
+
+// HandleApply imports a bundle, creating a new checkout in an empty directory
+// or fast-forwarding the bundled environment of an existing one. Only the local
+// HEAD moves, so the bundled commits are still pushed to the remote.
+func (h *Bundle) HandleApply(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: %s bundle apply FILE", core.AppName)
+	}
+
+	sealed, err := os.ReadFile(cmd.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	passphrase, err := h.passphrase(false)
+	if err != nil {
+		return err
+	}
+	data, err := h.cryptService.OpenWithPassphrase(passphrase, sealed)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt bundle: %w", err)
+	}
+
+	var bundle core.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("%w: %v", core.ErrInvalidBundle, err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return err
+	}
+	client := remote.NewBundleClient(bundle)
+
+	exists, err := h.appService.Exists()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := h.clone.withBundle(client).clone(bundle.Project.ID); err != nil {
+			return err
+		}
+		h.slate.ShowSuccess(fmt.Sprintf("Created a checkout of '%s' at %s", bundle.Environment.Name, bundle.Head))
+		return nil
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load project: %w", err)
+	}
+	if project.ID != bundle.Project.ID {
+		return fmt.Errorf("the bundle belongs to project %s, but this is project %s", bundle.Project.ID, project.ID)
+	}
+
+	env := bundle.Environment.Name
+	envExists, err := h.envService.EnvExists(env)
+	if err != nil {
+		return fmt.Errorf("failed to check environment: %w", err)
+	}
+	if !envExists {
+		if err := h.envService.CreateEnv(env); err != nil {
+			return err
+		}
+	}
+	// A history already past the bundle has nothing to take from it
+	if _, err := h.commitService.GetCommit(env, bundle.Head); err == nil {
+		h.slate.WriteColoredText("Already up to date.", "")
+		return nil
+	}
+
+	pull := h.pull.withBundle(client)
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead != "" {
+		// Local commits on top of the bundle's base are a divergence, not a gap
+		_, outside := bundle.CommitsAfter(head.LocalHead)
+		_, missingBase := h.commitService.GetCommit(env, bundle.BaseCommit)
+		if outside != nil && (bundle.BaseCommit == "" || missingBase == nil) {
+			return pull.reportDivergence(env, core.FetchedCommits{BaseCommit: bundle.BaseCommit, Head: bundle.Head, Commits: bundle.Commits})
+		}
+	}
+	return pull.pullFastForward(env)
+}
+
+// passphrase reads the bundle passphrase from the environment or prompts for it
+func (h *Bundle) passphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(bundlePassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	passphrase := h.slate.PromptSecret("Bundle passphrase")
+	if passphrase == "" {
+		return "", fmt.Errorf("a passphrase is required; enter one or set %s", bundlePassphraseEnv)
+	}
+	if confirm && h.slate.PromptSecret("Repeat passphrase") != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
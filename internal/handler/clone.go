@@ -18,6 +18,8 @@ type Clone struct {
 	apiClient      apiClient
 	slate          slate
 	appService     appService
+	// bundle creates the checkout from a bundle, which does not link it to the remote
+	bundle bool
 }
 
 func NewCloneHandler(projectService projectService, envService envService, secretService secretService, commitService commitService, cryptService cryptService, apiClient apiClient, slate slate, appService appService) *Clone {
@@ -38,7 +40,25 @@ func (h *Clone) Handle(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("usage: %s clone PROJECT_SLUG", core.AppName)
 	}
 
-	slug := cmd.Args().Get(0)
+	return h.clone(cmd.Args().Get(0))
+}
+
+// withClient returns a copy of the handler that clones through client
+func (h *Clone) withClient(client apiClient) *Clone {
+	clone := *h
+	clone.apiClient = client
+	return &clone
+}
+
+// withBundle returns a copy of the handler that clones the bundle behind client
+func (h *Clone) withBundle(client apiClient) *Clone {
+	clone := h.withClient(client)
+	clone.bundle = true
+	return clone
+}
+
+// clone creates a checkout of a project in the working directory
+func (h *Clone) clone(slug string) error {
 	h.slate.StartSpinner("Cloning project...")
 	resp, err := h.apiClient.Clone(remote.CloneRequest{ProjectSlug: slug})
 	if err != nil {
@@ -77,14 +97,28 @@ func (h *Clone) Handle(ctx context.Context, cmd *cli.Command) error {
 	h.slate.UpdateSpinner("Importing commits...")
 	var latestCommit *core.Commit
 	for _, commit := range data.Commits {
-		addedCommit, err := h.commitService.AddCommit(commit.ID, data.Environment.Name, commit.Message, commit.Author, commit.Changes, commit.Timestamp)
+		addedCommit, err := h.commitService.ImportCommit(data.Environment.Name, commit)
 		if err != nil {
 			h.slate.UpdateSpinner(fmt.Sprintf("Failed to import commit '%s': %v", commit.ID, err))
+			continue
 		}
 		latestCommit = addedCommit
 		h.slate.UpdateSpinner(fmt.Sprintf("Imported commit '%s'", addedCommit.ID))
 	}
-	h.commitService.UpdateRemoteHead(data.Environment.Name, latestCommit.ID)
+	if latestCommit != nil && !h.bundle {
+		h.commitService.UpdateRemoteHead(data.Environment.Name, latestCommit.ID)
+	}
+
+	if len(data.Tags) > 0 {
+		h.slate.UpdateSpinner("Importing tags...")
+		importTags := h.commitService.ImportTags
+		if h.bundle {
+			importTags = h.commitService.ImportLocalTags
+		}
+		if err := importTags(data.Environment.Name, data.Tags); err != nil {
+			h.slate.UpdateSpinner(fmt.Sprintf("Failed to import tags: %v", err))
+		}
+	}
 
 	h.slate.UpdateSpinner("Importing secrets...")
 	for _, secret := range data.Secrets {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jawahars16/jebi/internal/core"
 	"github.com/jawahars16/jebi/internal/ui"
 	"github.com/urfave/cli/v3"
 )
@@ -53,6 +54,13 @@ func (h *Commit) Handle(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to get current environment: %w", err)
 	}
 
+	if cmd.Bool("amend") {
+		return h.amend(env, msg, currentEnv.Changes)
+	}
+	if msg == "" {
+		return fmt.Errorf("a commit message is required: %s commit -m 'message'", core.AppName)
+	}
+
 	if len(currentEnv.Changes) == 0 {
 		h.slate.WriteStyledText("No changes to commit", ui.StyleOptions{
 			Color:   "178", // Yellow/amber
@@ -84,9 +92,10 @@ func (h *Commit) Handle(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
+	warnReusedCommit(h.slate, commit, msg, author)
 
 	// Clear uncommitted changes after successful commit using the existing service
-	err = h.changeRecordService.ClearPendingChanges()
+	err = h.changeRecordService.ClearPendingChanges(env)
 	if err != nil {
 		return fmt.Errorf("failed to clear uncommitted changes: %w", err)
 	}
@@ -103,4 +112,48 @@ func (h *Commit) Handle(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// warnReusedCommit tells the user when the changes matched an existing commit
+// (e.g. one undone earlier), which was restored with its own message and author
+func warnReusedCommit(slate slate, commit *core.Commit, message, author string) {
+	if commit.Message == message && commit.Author == author {
+		return
+	}
+	slate.ShowWarning(fmt.Sprintf(
+		"These changes were already committed as %s (%q by %s); that commit was restored\n"+
+			"instead of creating a new one. Run `%s commit --amend -m MESSAGE` to change its message.",
+		commit.ID, commit.Message, commit.Author, core.AppName))
+}
+
+// amend folds pending changes and/or a new message into the local HEAD commit
+func (h *Commit) amend(env, msg string, changes []core.Change) error {
+	if err := ensureNoRebase(h.commitService, env); err != nil {
+		return err
+	}
+	if msg == "" && len(changes) == 0 {
+		h.slate.WriteStyledText("Nothing to amend: no pending changes and no new message", ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+
+	commit, err := h.commitService.AmendCommit(env, msg, h.userService.GetCommitAuthor(), changes, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to amend commit: %w", err)
+	}
+
+	if err := h.changeRecordService.ClearPendingChanges(env); err != nil {
+		return fmt.Errorf("failed to clear uncommitted changes: %w", err)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	renderer := ui.NewCommitRenderer(h.slate)
+	renderer.RenderSingleCommit(*commit, head)
+	return nil
+}
+
 // displayCommit renders the newly created commit in a beautiful format
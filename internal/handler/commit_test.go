@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// fixedUser commits as a fixed author without logging in
+type fixedUser struct {
+	author string
+}
+
+func (u fixedUser) AuthenticateWithBrowser(server string) (*core.AuthResponse, error) {
+	return nil, nil
+}
+func (u fixedUser) SaveAuthToken(token string) error     { return nil }
+func (u fixedUser) LoadAuthToken() (string, error)       { return "", nil }
+func (u fixedUser) SaveCurrentUser(user core.User) error { return nil }
+func (u fixedUser) LoadCurrentUser() (*core.User, error) { return &core.User{}, nil }
+func (u fixedUser) Logout() error                        { return nil }
+func (u fixedUser) GetSystemUsername() string            { return u.author }
+func (u fixedUser) GetCommitAuthor() string              { return u.author }
+
+var commitFlags = []cli.Flag{
+	&cli.StringFlag{Name: "message", Aliases: []string{"m"}},
+	&cli.BoolFlag{Name: "amend"},
+}
+
+func TestCommitWarnsWhenReusingCommit(t *testing.T) {
+	store := io.NewMemoryStore()
+	envService := core.NewEnvServiceWithStore(store)
+	commitService := core.NewCommitServiceWithStore(store, nil)
+	changeRecordService := core.NewChangeRecordServiceWithStore(store)
+	slate := &quietSlate{}
+	h := NewCommitHandler(envService, commitService, changeRecordService, fixedUser{author: "alice"},
+		core.NewSecretServiceWithStore(store), core.NewProjectServiceWithStore(store), slate)
+
+	require.NoError(t, envService.CreateEnv("dev"))
+	require.NoError(t, envService.SetCurrentEnv("dev"))
+	require.NoError(t, changeRecordService.AddChangeRecord("dev", string(core.ChangeTypeAdd), "A", "1", "", true))
+	require.NoError(t, runActionWithFlags(t, h.Handle, commitFlags, "-m", "first"))
+	head, err := commitService.GetHead("dev")
+	require.NoError(t, err)
+	first := head.LocalHead
+	assert.Empty(t, slate.warnings)
+
+	// Undo the commit and record the same change under a new message
+	require.NoError(t, commitService.UpdateLocalHead("dev", ""))
+	require.NoError(t, changeRecordService.AddChangeRecord("dev", string(core.ChangeTypeAdd), "A", "1", "", true))
+	require.NoError(t, runActionWithFlags(t, h.Handle, commitFlags, "-m", "second"))
+
+	head, err = commitService.GetHead("dev")
+	require.NoError(t, err)
+	assert.Equal(t, first, head.LocalHead)
+	require.Len(t, slate.warnings, 1, "Reusing a commit with another message should be reported")
+	assert.Contains(t, slate.warnings[0], `"first"`)
+}
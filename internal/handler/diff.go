@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+const maskedValue = "********"
+
+type Diff struct {
+	envService     envService
+	commitService  commitService
+	secretService  secretService
+	projectService projectService
+	cryptService   cryptService
+	slate          slate
+}
+
+func NewDiffHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *Diff {
+	return &Diff{
+		envService:     envService,
+		commitService:  commitService,
+		secretService:  secretService,
+		projectService: projectService,
+		cryptService:   cryptService,
+		slate:          slate,
+	}
+}
+
+func (h *Diff) Handle(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	var (
+		from, to           map[string]core.Secret
+		fromLabel, toLabel string
+	)
+
+	other := cmd.String("env")
+	switch {
+	case other != "":
+		// Working state of the current environment vs that of another
+		if cmd.Args().Len() > 0 {
+			return fmt.Errorf("usage: %s diff --env ENV (commits cannot be given with --env)", core.AppName)
+		}
+		exists, err := h.envService.EnvExists(other)
+		if err != nil {
+			return fmt.Errorf("failed to check environment: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("environment '%s' does not exist", other)
+		}
+		if from, err = h.workingState(project.ID, env); err != nil {
+			return err
+		}
+		if to, err = h.workingState(project.ID, other); err != nil {
+			return err
+		}
+		fromLabel, toLabel = env, other
+	case cmd.Args().Len() == 0:
+		// Committed state vs working state
+		head, err := h.commitService.GetHead(env)
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if from, err = h.commitService.ComputeState(env, head.LocalHead); err != nil {
+			return fmt.Errorf("failed to compute committed state: %w", err)
+		}
+		if to, err = h.workingState(project.ID, env); err != nil {
+			return err
+		}
+		fromLabel, toLabel = "HEAD", "working state"
+	case cmd.Args().Len() == 1:
+		fromLabel, toLabel = cmd.Args().Get(0), "working state"
+		if from, err = h.stateAt(env, fromLabel); err != nil {
+			return err
+		}
+		if to, err = h.workingState(project.ID, env); err != nil {
+			return err
+		}
+	default:
+		fromLabel, toLabel = cmd.Args().Get(0), cmd.Args().Get(1)
+		if from, err = h.stateAt(env, fromLabel); err != nil {
+			return err
+		}
+		if to, err = h.stateAt(env, toLabel); err != nil {
+			return err
+		}
+	}
+
+	diffs, err := core.DiffValues(from, to, newDecrypter(h.cryptService, project.ID))
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		h.slate.WriteStyledText(fmt.Sprintf("No differences between %s and %s", fromLabel, toLabel), ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	formatValue, err := newValueFormatter(h.cryptService, project.ID, cmd.Bool("reveal"))
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("Diff %s → %s - Environment: %s", fromLabel, toLabel, env)
+	if other != "" {
+		title = fmt.Sprintf("Diff %s → %s - Environments", fromLabel, toLabel)
+	}
+	h.slate.WriteStyledText(title, ui.StyleOptions{
+		Color:  "82", // Light green
+		Bold:   true,
+		Margin: []int{0, 0, 1, 0}, // Bottom margin
+	})
+
+	renderer := ui.NewDiffRenderer(h.slate)
+	renderer.RenderDiff(diffs, formatValue)
+	return nil
+}
+
+// stateAt computes the secret state of an environment as of the given commit reference
+func (h *Diff) stateAt(env, ref string) (map[string]core.Secret, error) {
+	commit, err := h.commitService.ResolveCommit(env, ref)
+	if err != nil {
+		return nil, err
+	}
+	state, err := h.commitService.ComputeState(env, commit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute state at %s: %w", commit.ID, err)
+	}
+	return state, nil
+}
+
+// workingState reads the current (possibly uncommitted) secrets from disk
+func (h *Diff) workingState(projectID, env string) (map[string]core.Secret, error) {
+	secrets, err := h.secretService.ListSecrets(projectID, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return core.SecretsToState(secrets), nil
+}
+
+// newDecrypter returns a function that decrypts secret values with the
+// project's key, loading the key on first use
+func newDecrypter(cryptService cryptService, projectID string) func(core.Secret) (string, error) {
+	var key []byte
+	return func(secret core.Secret) (string, error) {
+		if secret.Nonce == "" {
+			return secret.Value, nil
+		}
+		if key == nil {
+			loaded, err := cryptService.LoadKey(projectID)
+			if err != nil {
+				return "", fmt.Errorf("failed to retrieve encryption key: %w", err)
+			}
+			key = loaded
+		}
+		return cryptService.Decrypt(key, secret.Value, secret.Nonce)
+	}
+}
+
+// newValueFormatter returns a function that displays secret values either
+// masked or, when reveal is set, decrypted with the project's key
+func newValueFormatter(cryptService cryptService, projectID string, reveal bool) (func(core.Secret) string, error) {
+	if !reveal {
+		return func(core.Secret) string { return maskedValue }, nil
+	}
+
+	key, err := cryptService.LoadKey(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	return func(secret core.Secret) string {
+		if secret.Nonce == "" {
+			return secret.Value
+		}
+		plaintext, err := cryptService.Decrypt(key, secret.Value, secret.Nonce)
+		if err != nil {
+			return "<unable to decrypt>"
+		}
+		return plaintext
+	}, nil
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/jawahars16/jebi/internal/core"
 	"github.com/jawahars16/jebi/internal/ui"
@@ -10,14 +11,35 @@ import (
 )
 
 type Env struct {
-	envService envService
-	slate      slate
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	stashService        stashService
+	projectService      projectService
+	cryptService        cryptService
+	slate               slate
 }
 
-func NewEnvHandler(envService envService, slate slate) *Env {
+func NewEnvHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	stashService stashService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *Env {
 	return &Env{
-		envService: envService,
-		slate:      slate,
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		stashService:        stashService,
+		projectService:      projectService,
+		cryptService:        cryptService,
+		slate:               slate,
 	}
 }
 
@@ -36,12 +58,33 @@ func (h *Env) HandleList(ctx context.Context, cmd *cli.Command) error {
 
 func (h *Env) HandleNew(ctx context.Context, cmd *cli.Command) error {
 	if cmd.Args().Len() < 1 {
-		return fmt.Errorf("usage: %s env new <name>", core.AppName)
+		return fmt.Errorf("usage: %s env new <name> [--from <env> [--keys-only]]", core.AppName)
 	}
 	env := cmd.Args().Get(0)
+	from := cmd.String("from")
+
+	exists, err := h.envService.EnvExists(env)
+	if err != nil {
+		return fmt.Errorf("failed to check environment '%s': %w", env, err)
+	}
+	if exists && from != "" {
+		return fmt.Errorf("environment '%s' already exists", env)
+	}
+
+	var seed []core.Change
+	if from != "" {
+		seed, err = h.seedChanges(from, cmd.Bool("keys-only"))
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := h.envService.CreateEnv(env); err != nil {
 		return err
 	}
+	if err := stageChanges(h.secretService, h.changeRecordService, env, seed); err != nil {
+		return err
+	}
 	if err := h.envService.SetCurrentEnv(env); err != nil {
 		return err
 	}
@@ -49,14 +92,118 @@ func (h *Env) HandleNew(ctx context.Context, cmd *cli.Command) error {
 		Color: "34", // Green
 		Bold:  true,
 	})
+	if from != "" {
+		h.slate.WriteIndentedText(fmt.Sprintf("Staged %d key(s) from '%s'. Review them and run `%s commit`.", len(seed), from, core.AppName), ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+	}
 	return nil
 }
 
+// seedChanges builds add changes for every committed key of an environment,
+// re-encrypted with fresh nonces, or with empty placeholder values when keysOnly is set
+func (h *Env) seedChanges(from string, keysOnly bool) ([]core.Change, error) {
+	exists, err := h.envService.EnvExists(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check environment '%s': %w", from, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("environment '%s' does not exist", from)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	key, err := h.cryptService.LoadKey(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	state, err := committedState(h.commitService, from)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(state))
+	for k := range state {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if keysOnly {
+		placeholders := make(map[string]core.Secret, len(state))
+		for _, k := range keys {
+			placeholders[k] = core.Secret{Key: k, NoSecret: state[k].NoSecret}
+		}
+		state = placeholders
+	}
+
+	return promotionChanges(h.cryptService, key, state, nil, keys)
+}
+
 func (h *Env) HandleUse(ctx context.Context, cmd *cli.Command) error {
 	if cmd.Args().Len() < 1 {
 		return fmt.Errorf("usage: %s env use <name>", core.AppName)
 	}
 	env := cmd.Args().Get(0)
+
+	exists, err := h.envService.EnvExists(env)
+	if err != nil {
+		return fmt.Errorf("failed to check if environment exists: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("environment '%s' does not exist", env)
+	}
+
+	current, err := h.envService.CurrentEnv()
+	if err != nil {
+		return err
+	}
+
+	if current != "" && current != env {
+		changes, err := h.changeRecordService.GetPendingChanges(current)
+		if err != nil {
+			return fmt.Errorf("failed to get pending changes: %w", err)
+		}
+
+		if len(changes) > 0 {
+			switch {
+			case cmd.Bool("carry"):
+				if err := h.carryChanges(current, env, changes); err != nil {
+					return err
+				}
+				h.slate.WriteIndentedText(fmt.Sprintf("Carried %d pending change(s) from '%s'", len(changes), current), ui.StyleOptions{
+					Color: "248", // Gray
+				})
+			case cmd.Bool("stash"):
+				entry, err := stashPendingChanges(h.commitService, h.secretService, h.changeRecordService, h.stashService, current, "")
+				if err != nil {
+					return err
+				}
+				h.slate.WriteIndentedText(fmt.Sprintf("Stashed %d pending change(s) from '%s': %s", len(changes), current, entry.Message), ui.StyleOptions{
+					Color: "248", // Gray
+				})
+			case cmd.Bool("keep"):
+				h.slate.WriteIndentedText(fmt.Sprintf("Kept %d pending change(s) staged in '%s'", len(changes), current), ui.StyleOptions{
+					Color: "248", // Gray
+				})
+			default:
+				h.slate.ShowWarning(fmt.Sprintf(
+					"Environment '%s' has %d uncommitted change(s).\n"+
+						"Commit them, or switch with:\n"+
+						"  --keep   leave them staged in '%s'\n"+
+						"  --carry  move them to '%s'\n"+
+						"  --stash  shelve them with `%s stash`",
+					current, len(changes), current, env, core.AppName))
+				h.slate.WriteStatus(changes)
+				return nil
+			}
+		}
+	}
+
 	if err := h.envService.SetCurrentEnv(env); err != nil {
 		return err
 	}
@@ -68,6 +215,55 @@ func (h *Env) HandleUse(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// HandleRename renames an environment while keeping its history, tags and stash entries
+func (h *Env) HandleRename(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 2 {
+		return fmt.Errorf("usage: %s env rename <old> <new>", core.AppName)
+	}
+	oldName, newName := cmd.Args().Get(0), cmd.Args().Get(1)
+
+	// The steps below run in the command's journal, so a failure in any of
+	// them rolls back the whole rename
+	if err := h.envService.RenameEnv(oldName, newName); err != nil {
+		return err
+	}
+
+	if err := h.commitService.RelabelCommits(newName, oldName); err != nil {
+		return fmt.Errorf("failed to update the environment's commits: %w", err)
+	}
+
+	// The remote still has the environment under its old name
+	head, err := h.commitService.GetHead(newName)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if err := h.commitService.ForgetRemote(newName); err != nil {
+		return err
+	}
+
+	if err := h.stashService.RenameEnv(oldName, newName); err != nil {
+		return fmt.Errorf("failed to update stash entries: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project.DefaultEnvironment == oldName {
+		project.DefaultEnvironment = newName
+		if err := h.projectService.UpdateProjectConfig(project); err != nil {
+			return fmt.Errorf("failed to update the default environment: %w", err)
+		}
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Renamed environment '%s' to '%s'", oldName, newName))
+
+	if head.RemoteHead != "" {
+		h.slate.ShowWarning(fmt.Sprintf("'%s' is no longer linked to '%s' on the remote; the next push publishes its whole history as '%s'.", newName, oldName, newName))
+	}
+	return nil
+}
+
 func (h *Env) HandleRemove(ctx context.Context, cmd *cli.Command) error {
 	if cmd.Args().Len() < 1 {
 		return fmt.Errorf("usage: %s env remove <name>", core.AppName)
@@ -79,3 +275,12 @@ func (h *Env) HandleRemove(ctx context.Context, cmd *cli.Command) error {
 	h.slate.RenderMarkdown(fmt.Sprintf("Removed environment `%s`", env))
 	return nil
 }
+
+// carryChanges moves pending changes from one environment to another and
+// restores the source environment to its committed state
+func (h *Env) carryChanges(from, to string, changes []core.Change) error {
+	if err := stageChanges(h.secretService, h.changeRecordService, to, changes); err != nil {
+		return err
+	}
+	return discardUncommitted(h.commitService, h.secretService, h.changeRecordService, from)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+// HandleDiff prints which keys exist in which environments and whether their
+// values match, using keyed fingerprints instead of the values themselves
+func (h *Env) HandleDiff(ctx context.Context, cmd *cli.Command) error {
+	format := cmd.String("output")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported output format '%s' (use table or json)", format)
+	}
+
+	envs := cmd.Args().Slice()
+	if len(envs) == 0 {
+		all, err := h.envService.ListEnvs()
+		if err != nil {
+			return err
+		}
+		envs = all
+	}
+	if len(envs) < 2 {
+		return fmt.Errorf("usage: %s env diff ENV ENV [ENV...]", core.AppName)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	key, err := h.cryptService.LoadKey(project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	values := make(map[string]map[string]string, len(envs))
+	for _, env := range envs {
+		exists, err := h.envService.EnvExists(env)
+		if err != nil {
+			return fmt.Errorf("failed to check environment '%s': %w", env, err)
+		}
+		if !exists {
+			return fmt.Errorf("environment '%s' does not exist", env)
+		}
+
+		secrets, err := h.secretService.ListSecrets(project.ID, env)
+		if err != nil {
+			return fmt.Errorf("failed to list secrets of '%s': %w", env, err)
+		}
+		values[env] = make(map[string]string, len(secrets))
+		for _, secret := range secrets {
+			plaintext := secret.Value
+			if secret.Nonce != "" {
+				plaintext, err = h.cryptService.Decrypt(key, secret.Value, secret.Nonce)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt %s in '%s': %w", secret.Key, env, err)
+				}
+			}
+			values[env][secret.Key] = plaintext
+		}
+	}
+
+	comparison := core.CompareEnvironments(envs, values, core.DeriveFingerprintKey(key))
+
+	if format == "json" {
+		output, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode comparison: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	h.renderComparison(comparison)
+	return nil
+}
+
+// renderComparison prints the comparison as a table. Within a row, environments
+// sharing a value get the same number (#1, #2, ...); missing keys are shown as "-".
+func (h *Env) renderComparison(comparison core.EnvComparison) {
+	if len(comparison.Keys) == 0 {
+		h.slate.WriteStyledText("No secrets in these environments", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return
+	}
+
+	keyWidth := len("KEY")
+	for _, row := range comparison.Keys {
+		keyWidth = max(keyWidth, len(row.Key))
+	}
+	columnWidths := make([]int, len(comparison.Environments))
+	for i, env := range comparison.Environments {
+		columnWidths[i] = max(len(env), len(fmt.Sprintf("#%d", len(comparison.Environments))))
+	}
+
+	header := fmt.Sprintf("%-*s", keyWidth, "KEY")
+	for i, env := range comparison.Environments {
+		header += fmt.Sprintf("  %-*s", columnWidths[i], env)
+	}
+	h.slate.WriteIndentedText(header+"  STATUS", ui.StyleOptions{
+		Color: "15", // White
+		Bold:  true,
+	})
+
+	counts := make(map[core.KeyStatus]int)
+	for _, row := range comparison.Keys {
+		counts[row.Status]++
+
+		labels := make(map[string]string)
+		line := fmt.Sprintf("%-*s", keyWidth, row.Key)
+		for i, env := range comparison.Environments {
+			cell := "-"
+			if fingerprint, exists := row.Fingerprints[env]; exists {
+				if _, seen := labels[fingerprint]; !seen {
+					labels[fingerprint] = fmt.Sprintf("#%d", len(labels)+1)
+				}
+				cell = labels[fingerprint]
+			}
+			line += fmt.Sprintf("  %-*s", columnWidths[i], cell)
+		}
+
+		status := string(row.Status)
+		if len(row.MissingIn) > 0 {
+			status = fmt.Sprintf("missing in %s", strings.Join(row.MissingIn, ", "))
+		}
+		h.slate.WriteIndentedText(line+"  "+status, ui.StyleOptions{
+			Color: comparisonColor(row.Status),
+		})
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("%d identical, %d different, %d missing somewhere",
+		counts[core.KeyIdentical], counts[core.KeyDifferent], counts[core.KeyMissing]), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+		Margin: []int{1, 0, 0, 0}, // Top margin
+	})
+}
+
+func comparisonColor(status core.KeyStatus) lipgloss.Color {
+	switch status {
+	case core.KeyIdentical:
+		return "34" // Green
+	case core.KeyDifferent:
+		return "214" // Orange
+	default:
+		return "196" // Red
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// quietSlate discards output and records warnings
+type quietSlate struct {
+	warnings []string
+}
+
+func (s *quietSlate) PromptWithDefault(message, defaultValue string) string      { return defaultValue }
+func (s *quietSlate) PromptSecret(message string) string                         { return "" }
+func (s *quietSlate) ShowHeader(title string)                                    {}
+func (s *quietSlate) ShowList(title string, items []string, highlight string)    {}
+func (s *quietSlate) WriteStatus(changes []core.Change)                          {}
+func (s *quietSlate) RenderMarkdown(md string)                                   {}
+func (s *quietSlate) ShowWarning(msg string)                                     { s.warnings = append(s.warnings, msg) }
+func (s *quietSlate) ShowError(msg string)                                       {}
+func (s *quietSlate) WriteStyledText(text string, options ui.StyleOptions)       {}
+func (s *quietSlate) WriteColoredText(text string, color lipgloss.Color)         {}
+func (s *quietSlate) WriteIndentedText(text string, options ui.StyleOptions)     {}
+func (s *quietSlate) ShowSuccess(message string)                                 {}
+func (s *quietSlate) ShowEnvironmentContext(env string)                          {}
+func (s *quietSlate) RenderInitHeader()                                          {}
+func (s *quietSlate) StartSpinner(message string)                                {}
+func (s *quietSlate) UpdateSpinner(newMessage ...string)                         {}
+func (s *quietSlate) StopSpinner()                                               {}
+func (s *quietSlate) StopSpinnerWithError(errorMessage string)                   {}
+func (s *quietSlate) ShowSpinnerOperation(message string, op func() error) error { return op() }
+func (s *quietSlate) ShowSecretOperation(operation core.ChangeType, key, env string, isPlaintext bool) {
+}
+
+// runAction invokes a handler action as the CLI would, with args after the command name
+func runAction(t *testing.T, action cli.ActionFunc, args ...string) error {
+	t.Helper()
+	return runActionWithFlags(t, action, nil, args...)
+}
+
+// runActionWithFlags is runAction for a command that defines flags
+func runActionWithFlags(t *testing.T, action cli.ActionFunc, flags []cli.Flag, args ...string) error {
+	t.Helper()
+	cmd := &cli.Command{Name: "test", Flags: flags, Action: action}
+	return cmd.Run(context.Background(), append([]string{"test"}, args...))
+}
+
+func TestEnvRenameOnMemoryStore(t *testing.T) {
+	store := io.NewMemoryStore()
+	envService := core.NewEnvServiceWithStore(store)
+	commitService := core.NewCommitServiceWithStore(store, nil)
+	stashService := core.NewStashServiceWithStore(store)
+	projectService := core.NewProjectServiceWithStore(store)
+	slate := &quietSlate{}
+	h := NewEnvHandler(envService, commitService, core.NewSecretServiceWithStore(store), core.NewChangeRecordServiceWithStore(store), stashService, projectService, nil, slate)
+
+	_, err := projectService.SaveProjectConfig("p1", "demo", "", "staging")
+	require.NoError(t, err)
+	require.NoError(t, envService.CreateEnv("dev"))
+	require.NoError(t, envService.CreateEnv("staging"))
+	require.NoError(t, envService.SetCurrentEnv("staging"))
+	commit, err := commitService.AddCommit("", "staging", "add A", "alice", []core.Change{{Type: core.ChangeTypeAdd, Key: "A", Value: "1"}}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, commitService.UpdateRemoteHead("staging", commit.ID))
+	require.NoError(t, stashService.Push(core.StashEntry{Env: "staging", Message: "wip"}))
+
+	require.NoError(t, runAction(t, h.HandleRename, "staging", "qa"))
+
+	envs, err := envService.ListEnvs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev", "qa"}, envs)
+	current, err := envService.CurrentEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "qa", current)
+
+	head, err := commitService.GetHead("qa")
+	require.NoError(t, err)
+	assert.Equal(t, commit.ID, head.LocalHead)
+	assert.Empty(t, head.RemoteHead, "The renamed environment is not on the remote under its new name")
+	require.Len(t, slate.warnings, 1)
+
+	stash, err := stashService.List()
+	require.NoError(t, err)
+	require.Len(t, stash, 1)
+	assert.Equal(t, "qa", stash[0].Env)
+	project, err := projectService.LoadProjectConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "qa", project.DefaultEnvironment)
+
+	assert.Error(t, runAction(t, h.HandleRename, "missing", "other"))
+}
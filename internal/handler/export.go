@@ -12,14 +12,16 @@ import (
 
 type Export struct {
 	envService     envService
+	commitService  commitService
 	cryptService   cryptService
 	projectService projectService
 	slate          slate
 }
 
-func NewExportHandler(envService envService, cryptService cryptService, projectService projectService, slate slate) *Export {
+func NewExportHandler(envService envService, commitService commitService, cryptService cryptService, projectService projectService, slate slate) *Export {
 	return &Export{
 		envService:     envService,
+		commitService:  commitService,
 		cryptService:   cryptService,
 		projectService: projectService,
 		slate:          slate,
@@ -38,9 +40,9 @@ func (h *Export) Handle(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to get project: %w", err)
 	}
 
-	secrets, err := h.cryptService.LoadSecrets(project.ID, env)
+	secrets, err := loadSecrets(h.commitService, h.cryptService, project.ID, env, cmd.String("at"))
 	if err != nil {
-		return fmt.Errorf("failed to load secrets: %w", err)
+		return err
 	}
 	projectName := sanitizeK8sName(project.Name)
 	output, err := io.Export(format, secrets, env, projectName)
@@ -52,6 +54,46 @@ func (h *Export) Handle(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// loadSecrets returns the decrypted secrets of the working state, or of the
+// commit or tag given by ref when it is not empty
+func loadSecrets(commitService commitService, cryptService cryptService, projectID, env, ref string) (map[string]string, error) {
+	if ref == "" {
+		secrets, err := cryptService.LoadSecrets(projectID, env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secrets: %w", err)
+		}
+		return secrets, nil
+	}
+
+	commit, err := commitService.ResolveCommit(env, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := commitService.ComputeState(env, commit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute state at %s: %w", commit.ID, err)
+	}
+
+	key, err := cryptService.LoadKey(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+
+	secrets := make(map[string]string, len(state))
+	for k, secret := range state {
+		value := secret.Value
+		if secret.Nonce != "" {
+			value, err = cryptService.Decrypt(key, secret.Value, secret.Nonce)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", k, err)
+			}
+		}
+		secrets[k] = value
+	}
+	return secrets, nil
+}
+
 func sanitizeK8sName(name string) string {
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, "_", "-")
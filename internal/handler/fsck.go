@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Fsck struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	projectService      projectService
+	cryptService        cryptService
+	slate               slate
+}
+
+func NewFsckHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *Fsck {
+	return &Fsck{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		projectService:      projectService,
+		cryptService:        cryptService,
+		slate:               slate,
+	}
+}
+
+func (h *Fsck) Handle(ctx context.Context, cmd *cli.Command) error {
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	key, err := h.cryptService.LoadKey(project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	envs, err := h.envService.ListEnvs()
+	if err != nil {
+		return fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	var errorCount, warningCount int
+	for _, env := range envs {
+		issues, err := h.checkEnv(project.ID, env, key)
+		if err != nil {
+			return err
+		}
+
+		h.slate.WriteStyledText(fmt.Sprintf("Environment: %s", env), ui.StyleOptions{
+			Color: "82", // Light green
+			Bold:  true,
+		})
+		if len(issues) == 0 {
+			h.slate.WriteIndentedText("ok", ui.StyleOptions{
+				Color: "34", // Green
+			})
+			continue
+		}
+
+		for _, issue := range issues {
+			color := lipgloss.Color("178") // Yellow/amber
+			if issue.Severity == core.SeverityError {
+				color = "196" // Red
+				errorCount++
+			} else {
+				warningCount++
+			}
+			h.slate.WriteIndentedText(fmt.Sprintf("%s: %s", issue.Severity, issue.Message), ui.StyleOptions{
+				Color: color,
+			})
+		}
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("Checked %d environment(s): %d error(s), %d warning(s)", len(envs), errorCount, warningCount), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+		Margin: []int{1, 0, 0, 0}, // Top margin
+	})
+
+	if errorCount > 0 {
+		return fmt.Errorf("integrity check failed with %d error(s)", errorCount)
+	}
+	return nil
+}
+
+// checkEnv runs every integrity check against a single environment
+func (h *Fsck) checkEnv(projectID, env string, key []byte) ([]core.IntegrityIssue, error) {
+	issues, err := h.commitService.CheckHistory(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check history of '%s': %w", env, err)
+	}
+	report := func(format string, args ...any) {
+		issues = append(issues, core.IntegrityIssue{Env: env, Severity: core.SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	secrets, err := h.secretService.ListSecrets(projectID, env)
+	if err != nil {
+		report("secrets file is unreadable: %v", err)
+		return issues, nil
+	}
+	working := core.SecretsToState(secrets)
+
+	for _, secret := range secrets {
+		if secret.Nonce == "" {
+			continue
+		}
+		if _, err := h.cryptService.Decrypt(key, secret.Value, secret.Nonce); err != nil {
+			report("secret '%s' in the secrets file does not decrypt", secret.Key)
+		}
+	}
+
+	commits, err := h.commitService.ListCommits(env)
+	if err != nil {
+		report("commits file is unreadable: %v", err)
+		return issues, nil
+	}
+	for _, commit := range commits {
+		for _, change := range commit.Changes {
+			if change.Nonce == "" {
+				continue
+			}
+			if _, err := h.cryptService.Decrypt(key, change.Value, change.Nonce); err != nil {
+				report("value of '%s' in commit %s does not decrypt", change.Key, commit.ID)
+			}
+		}
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		report("HEAD is unreadable: %v", err)
+		return issues, nil
+	}
+	expected, err := h.commitService.ComputeState(env, head.LocalHead)
+	if err != nil {
+		// The history problems behind this are already reported above
+		return issues, nil
+	}
+	pending, err := h.changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		report("pending changes are unreadable: %v", err)
+		return issues, nil
+	}
+	core.ApplyChangesToState(expected, pending)
+	for _, diff := range core.DiffStates(expected, working) {
+		switch diff.Type {
+		case core.ChangeTypeAdd:
+			report("secret '%s' is in the secrets file but not in history or pending changes", diff.Key)
+		case core.ChangeTypeRemove:
+			report("secret '%s' is missing from the secrets file", diff.Key)
+		case core.ChangeTypeModify:
+			report("secret '%s' in the secrets file does not match history or pending changes", diff.Key)
+		}
+	}
+
+	return issues, nil
+}
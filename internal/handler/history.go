@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type History struct {
+	envService     envService
+	commitService  commitService
+	projectService projectService
+	cryptService   cryptService
+	slate          slate
+}
+
+func NewHistoryHandler(
+	envService envService,
+	commitService commitService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *History {
+	return &History{
+		envService:     envService,
+		commitService:  commitService,
+		projectService: projectService,
+		cryptService:   cryptService,
+		slate:          slate,
+	}
+}
+
+// Handle lists every committed version of a key, newest first
+func (h *History) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s history KEY [--reveal]", core.AppName)
+	}
+	key := cmd.Args().Get(0)
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	chain, err := h.commitService.GetCommitChain(env, head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to build commit chain: %w", err)
+	}
+
+	versions := core.KeyHistory(chain, key)
+	if len(versions) == 0 {
+		h.slate.WriteStyledText(fmt.Sprintf("No committed history for '%s' in %s", key, env), ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	formatValue, err := newValueFormatter(h.cryptService, project.ID, cmd.Bool("reveal"))
+	if err != nil {
+		return err
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("History of %s - Environment: %s", key, env), ui.StyleOptions{
+		Color:  "82", // Light green
+		Bold:   true,
+		Margin: []int{0, 0, 1, 0}, // Bottom margin
+	})
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i]
+		change := version.Change
+
+		h.slate.WriteStyledText(fmt.Sprintf("v%d  %s  %s  %s", version.Version, version.Commit.ID,
+			version.Commit.Timestamp.Format(blameTimeFormat), version.Commit.Author), ui.StyleOptions{
+			Color: "15", // White
+			Bold:  true,
+		})
+
+		switch change.Type {
+		case core.ChangeTypeRemove:
+			h.slate.WriteIndentedText("removed", ui.StyleOptions{
+				Color: "131", // Red
+			})
+		default:
+			value := core.Secret{Key: change.Key, Value: change.Value, Nonce: change.Nonce, NoSecret: change.NoSecret}
+			h.slate.WriteIndentedText(fmt.Sprintf("%s = %s", change.Type, formatValue(value)), ui.StyleOptions{
+				Color: "248", // Gray
+			})
+		}
+		if version.Commit.Message != "" {
+			h.slate.WriteIndentedText(version.Commit.Message, ui.StyleOptions{
+				Color:  "248", // Gray
+				Italic: true,
+			})
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jawahars16/jebi/internal/core"
@@ -14,6 +15,8 @@ type Init struct {
 	projectService projectService
 	envService     envService
 	cryptService   cryptService
+	signingService signingService
+	userService    userService
 	slate          slate
 }
 
@@ -22,6 +25,8 @@ func NewInitHandler(
 	projectService projectService,
 	envService envService,
 	cryptService cryptService,
+	signingService signingService,
+	userService userService,
 	slate slate,
 ) *Init {
 	return &Init{
@@ -29,6 +34,8 @@ func NewInitHandler(
 		projectService: projectService,
 		envService:     envService,
 		cryptService:   cryptService,
+		signingService: signingService,
+		userService:    userService,
 		slate:          slate,
 	}
 }
@@ -92,6 +99,30 @@ func (h *Init) Handle(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to save symmetric key: %w", err)
 	}
 
+	// Trust the creator's signing key so their commits verify from the start
+	if err := h.trustOwnKey(); err != nil {
+		h.slate.ShowWarning(fmt.Sprintf("Could not trust your signing key: %v\nRun '%s key trust' to do it later.", err, core.AppName))
+	}
+
 	fmt.Printf("\n✅ Project '%s' initialized successfully!\n", projectName)
 	return nil
 }
+
+func (h *Init) trustOwnKey() error {
+	publicKey, err := h.signingService.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	project.TrustedKeys = append(project.TrustedKeys, core.TrustedKey{
+		Name:      h.userService.GetCommitAuthor(),
+		PublicKey: publicKey,
+		AddedAt:   time.Now().UTC(),
+	})
+	return h.projectService.UpdateProjectConfig(project)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Key struct {
+	projectService projectService
+	signingService signingService
+	userService    userService
+	slate          slate
+}
+
+func NewKeyHandler(projectService projectService, signingService signingService, userService userService, slate slate) *Key {
+	return &Key{
+		projectService: projectService,
+		signingService: signingService,
+		userService:    userService,
+		slate:          slate,
+	}
+}
+
+// HandleShow prints the current user's public signing key
+func (h *Key) HandleShow(ctx context.Context, cmd *cli.Command) error {
+	publicKey, err := h.signingService.PublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	h.slate.WriteStyledText("Your public signing key:", ui.StyleOptions{
+		Color: "82", // Light green
+		Bold:  true,
+	})
+	h.slate.WriteIndentedText(publicKey, ui.StyleOptions{
+		Color: "15", // White
+	})
+	h.slate.WriteIndentedText(fmt.Sprintf("Fingerprint: %s", core.KeyFingerprint(publicKey)), ui.StyleOptions{
+		Color: "248", // Gray
+	})
+	h.slate.WriteIndentedText(fmt.Sprintf("Share it with a maintainer who can run `%s key trust <key> --name <name>`", core.AppName), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+	})
+	return nil
+}
+
+// HandleList lists the keys trusted to sign commits in this project
+func (h *Key) HandleList(ctx context.Context, cmd *cli.Command) error {
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if len(project.TrustedKeys) == 0 {
+		h.slate.WriteStyledText("No trusted signing keys", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	h.slate.WriteStyledText("Trusted signing keys:", ui.StyleOptions{
+		Color: "82", // Light green
+		Bold:  true,
+	})
+	for _, trusted := range project.TrustedKeys {
+		h.slate.WriteIndentedText(fmt.Sprintf("%s  %s", core.KeyFingerprint(trusted.PublicKey), trusted.Name), ui.StyleOptions{
+			Color: "15", // White
+		})
+	}
+	return nil
+}
+
+// HandleTrust adds a public key (or the current user's own key) to the trusted list
+func (h *Key) HandleTrust(ctx context.Context, cmd *cli.Command) error {
+	publicKey := cmd.Args().Get(0)
+	name := cmd.String("name")
+
+	if publicKey == "" {
+		ownKey, err := h.signingService.PublicKey()
+		if err != nil {
+			return fmt.Errorf("failed to load signing key: %w", err)
+		}
+		publicKey = ownKey
+		if name == "" {
+			name = h.userService.GetCommitAuthor()
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("usage: %s key trust [PUBLIC_KEY --name NAME]", core.AppName)
+	}
+	if err := core.ValidatePublicKey(publicKey); err != nil {
+		return err
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	for _, trusted := range project.TrustedKeys {
+		if trusted.PublicKey == publicKey {
+			h.slate.ShowWarning(fmt.Sprintf("Key %s is already trusted as '%s'", core.KeyFingerprint(publicKey), trusted.Name))
+			return nil
+		}
+	}
+
+	project.TrustedKeys = append(project.TrustedKeys, core.TrustedKey{
+		Name:      name,
+		PublicKey: publicKey,
+		AddedAt:   time.Now().UTC(),
+	})
+	if err := h.projectService.UpdateProjectConfig(project); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Trusted key %s for '%s'", core.KeyFingerprint(publicKey), name))
+	return nil
+}
+
+// HandleUntrust removes a key from the trusted list by public key, fingerprint or name
+func (h *Key) HandleUntrust(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s key untrust KEY|FINGERPRINT|NAME", core.AppName)
+	}
+	ref := cmd.Args().Get(0)
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	index := slices.IndexFunc(project.TrustedKeys, func(trusted core.TrustedKey) bool {
+		return trusted.PublicKey == ref || trusted.Name == ref || core.KeyFingerprint(trusted.PublicKey) == ref
+	})
+	if index < 0 {
+		return fmt.Errorf("no trusted key matches '%s'", ref)
+	}
+
+	removed := project.TrustedKeys[index]
+	project.TrustedKeys = slices.Delete(project.TrustedKeys, index, index+1)
+	if err := h.projectService.UpdateProjectConfig(project); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Removed trusted key %s ('%s')", core.KeyFingerprint(removed.PublicKey), removed.Name))
+	return nil
+}
@@ -9,16 +9,18 @@ import (
 )
 
 type Log struct {
-	envService    envService
-	commitService commitService
-	slate         slate
+	envService     envService
+	commitService  commitService
+	projectService projectService
+	slate          slate
 }
 
-func NewLogHandler(envService envService, commitService commitService, slate slate) *Log {
+func NewLogHandler(envService envService, commitService commitService, projectService projectService, slate slate) *Log {
 	return &Log{
-		envService:    envService,
-		commitService: commitService,
-		slate:         slate,
+		envService:     envService,
+		commitService:  commitService,
+		projectService: projectService,
+		slate:          slate,
 	}
 }
 
@@ -36,7 +38,7 @@ func (h *Log) Handle(ctx context.Context, cmd *cli.Command) error {
 		return nil
 	}
 
-	commits, err := h.commitService.ListCommits(env)
+	commits, err := h.commitService.ListHistory(env)
 	if err != nil {
 		return err
 	}
@@ -58,6 +60,11 @@ func (h *Log) Handle(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
 	// Show header
 	h.slate.WriteStyledText(fmt.Sprintf("Commit History - Environment: %s", env), ui.StyleOptions{
 		Color:  "82", // Light green
@@ -66,7 +73,7 @@ func (h *Log) Handle(ctx context.Context, cmd *cli.Command) error {
 	})
 
 	// Display each commit
-	renderer := ui.NewCommitRenderer(h.slate)
+	renderer := ui.NewCommitRenderer(h.slate).WithSignatureVerification(project.TrustedKeys)
 	for i, commit := range commits {
 		renderer.RenderCommit(commit, head, i > 0) // Add spacing for all but first commit
 	}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+// HandleMerge merges the commits downloaded by `jebi fetch` into the local history
+func (h *Pull) HandleMerge(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	inProgress, err := h.rebaseInProgress(env)
+	if err != nil {
+		return err
+	}
+	if inProgress {
+		return fmt.Errorf("%w in '%s'; run `%s pull --continue` or `%s pull --abort`", core.ErrRebaseInProgress, env, core.AppName, core.AppName)
+	}
+
+	fetched, err := h.commitService.GetFetched(env)
+	if err != nil {
+		return err
+	}
+	if len(fetched.Commits) == 0 {
+		return fmt.Errorf("nothing to merge; run `%s fetch` first", core.AppName)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead == fetched.BaseCommit {
+		return h.fastForward(env, *fetched)
+	}
+	return h.merge(env, *fetched, cmd)
+}
+
+// merge creates a merge commit joining the local HEAD and the fetched remote head
+func (h *Pull) merge(env string, fetched core.FetchedCommits, cmd *cli.Command) error {
+	if cmd.Bool("ours") && cmd.Bool("theirs") {
+		return fmt.Errorf("--ours and --theirs cannot be used together")
+	}
+
+	pending, err := h.changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		return fmt.Errorf("failed to get pending changes: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("'%s' has uncommitted changes; commit, stash or reset them before merging", env)
+	}
+
+	if err := h.commitService.ImportFetched(env, fetched); err != nil {
+		return err
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	baseID, err := h.commitService.MergeBase(env, head.LocalHead, fetched.Head)
+	if err != nil {
+		return err
+	}
+	if baseID == "" && head.LocalHead != "" {
+		return fmt.Errorf("%w: '%s' shares no commit with the remote; refusing to merge", core.ErrUnrelatedHistories, env)
+	}
+
+	base, err := h.commitService.ComputeState(env, baseID)
+	if err != nil {
+		return fmt.Errorf("failed to compute state at merge base: %w", err)
+	}
+	ours, err := h.commitService.ComputeState(env, head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to compute local state: %w", err)
+	}
+	theirs, err := h.commitService.ComputeState(env, fetched.Head)
+	if err != nil {
+		return fmt.Errorf("failed to compute remote state: %w", err)
+	}
+
+	result := core.ThreeWayMerge(base, ours, theirs)
+	if err := h.resolveConflicts(&result, cmd); err != nil {
+		return err
+	}
+
+	message := cmd.String("message")
+	if message == "" {
+		message = fmt.Sprintf("Merge remote commits into %s", env)
+	}
+	changes := core.ChangesBetween(ours, result.State)
+	commit, err := h.commitService.AddMergeCommit(env, message, h.userService.GetCommitAuthor(), changes, fetched.Head, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create merge commit: %w", err)
+	}
+
+	if err := h.commitService.UpdateRemoteHead(env, fetched.Head); err != nil {
+		return err
+	}
+	if err := h.secretService.ReplaceSecrets(env, result.State); err != nil {
+		return fmt.Errorf("failed to update secrets: %w", err)
+	}
+	if err := h.commitService.ImportTags(env, fetched.Tags); err != nil {
+		return err
+	}
+	if err := h.commitService.ClearFetched(env); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Merged %d remote commit(s) into '%s' as %s", len(fetched.Commits), env, commit.ID))
+	ui.NewDiffRenderer(h.slate).RenderDiff(core.DiffStates(ours, result.State), func(core.Secret) string { return maskedValue })
+	return nil
+}
+
+// resolveConflicts settles keys changed on both sides: values that decrypt to the
+// same plaintext are taken as is, the rest follow --ours/--theirs or a prompt
+func (h *Pull) resolveConflicts(result *core.MergeResult, cmd *cli.Command) error {
+	if len(result.Conflicts) == 0 {
+		return nil
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	key, err := h.cryptService.LoadKey(project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	for _, conflict := range append([]core.MergeConflict(nil), result.Conflicts...) {
+		if conflict.Ours != nil && conflict.Theirs != nil && conflict.Ours.NoSecret == conflict.Theirs.NoSecret {
+			ours, errOurs := decryptSecret(h.cryptService, key, *conflict.Ours)
+			theirs, errTheirs := decryptSecret(h.cryptService, key, *conflict.Theirs)
+			if errOurs == nil && errTheirs == nil && ours == theirs {
+				result.Resolve(conflict.Key, conflict.Ours)
+				continue
+			}
+		}
+
+		switch {
+		case cmd.Bool("ours"):
+			result.Resolve(conflict.Key, conflict.Ours)
+		case cmd.Bool("theirs"):
+			result.Resolve(conflict.Key, conflict.Theirs)
+		default:
+			h.slate.WriteStyledText(fmt.Sprintf("Conflict: %s was changed locally and on the remote", conflict.Key), ui.StyleOptions{
+				Color: "214", // Orange
+				Bold:  true,
+			})
+			h.slate.WriteIndentedText(fmt.Sprintf("ours:   %s", describeSide(conflict.Ours)), ui.StyleOptions{
+				Color: "248", // Gray
+			})
+			h.slate.WriteIndentedText(fmt.Sprintf("theirs: %s", describeSide(conflict.Theirs)), ui.StyleOptions{
+				Color: "248", // Gray
+			})
+			answer := strings.ToLower(h.slate.PromptWithDefault("Keep (o)urs or (t)heirs?", ""))
+			switch answer {
+			case "o", "ours":
+				result.Resolve(conflict.Key, conflict.Ours)
+			case "t", "theirs":
+				result.Resolve(conflict.Key, conflict.Theirs)
+			default:
+				return fmt.Errorf("merge aborted: %s is unresolved; rerun with --ours or --theirs", conflict.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// describeSide summarizes one side of a conflict without revealing its value
+func describeSide(secret *core.Secret) string {
+	if secret == nil {
+		return "removed"
+	}
+	return "changed to " + maskedValue
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Migrate struct {
+	migrationService migrationService
+	slate            slate
+}
+
+func NewMigrateHandler(migrationService migrationService, slate slate) *Migrate {
+	return &Migrate{
+		migrationService: migrationService,
+		slate:            slate,
+	}
+}
+
+// Handle upgrades the project layout, or lists the pending migrations with --dry-run
+func (h *Migrate) Handle(ctx context.Context, cmd *cli.Command) error {
+	version, err := h.migrationService.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	pending, err := h.migrationService.Pending()
+	if err != nil {
+		return err
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("Layout version %d (this %s supports %d)", version, core.AppName, core.LayoutVersion), ui.StyleOptions{
+		Color: "82", // Light green
+		Bold:  true,
+	})
+	if len(pending) == 0 {
+		h.slate.WriteIndentedText("The project layout is up to date", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	for _, migration := range pending {
+		h.slate.WriteIndentedText(fmt.Sprintf("v%d  %s", migration.Version, migration.Description), ui.StyleOptions{
+			Color: "15", // White
+		})
+	}
+
+	if cmd.Bool("dry-run") {
+		h.slate.WriteIndentedText(fmt.Sprintf("\nDry run: nothing was changed. Run `%s migrate` to apply %d migration(s).", core.AppName, len(pending)), ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	applied, err := h.migrationService.Migrate()
+	if err != nil {
+		return err
+	}
+	h.slate.ShowSuccess(fmt.Sprintf("Migrated the project layout to version %d (%d migration(s) applied)", core.LayoutVersion, len(applied)))
+	return nil
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Promote struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	projectService      projectService
+	cryptService        cryptService
+	slate               slate
+}
+
+func NewPromoteHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *Promote {
+	return &Promote{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		projectService:      projectService,
+		cryptService:        cryptService,
+		slate:               slate,
+	}
+}
+
+// Handle copies committed secrets from one environment into another as pending changes
+func (h *Promote) Handle(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args().Slice()
+	all := cmd.Bool("all")
+	if len(args) < 2 || (len(args) == 2 && !all) || (len(args) > 2 && all) {
+		return fmt.Errorf("usage: %s promote SRC DST KEY... | --all [--dry-run]", core.AppName)
+	}
+	src, dst, keys := args[0], args[1], args[2:]
+	if src == dst {
+		return fmt.Errorf("source and destination environments are the same")
+	}
+
+	for _, env := range []string{src, dst} {
+		exists, err := h.envService.EnvExists(env)
+		if err != nil {
+			return fmt.Errorf("failed to check environment '%s': %w", env, err)
+		}
+		if !exists {
+			return fmt.Errorf("environment '%s' does not exist", env)
+		}
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	key, err := h.cryptService.LoadKey(project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve encryption key: %w", err)
+	}
+
+	srcState, err := committedState(h.commitService, src)
+	if err != nil {
+		return err
+	}
+	if all {
+		for k := range srcState {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+
+	dstSecrets, err := h.secretService.ListSecrets(project.ID, dst)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets of '%s': %w", dst, err)
+	}
+	dstState := core.SecretsToState(dstSecrets)
+
+	changes, err := promotionChanges(h.cryptService, key, srcState, dstState, keys)
+	if err != nil {
+		return err
+	}
+
+	if dirty, err := h.envService.HasPendingChanges(src); err == nil && dirty {
+		h.slate.ShowWarning(fmt.Sprintf("'%s' has uncommitted changes; only committed values are promoted", src))
+	}
+
+	if len(changes) == 0 {
+		h.slate.WriteStyledText(fmt.Sprintf("'%s' is already up to date with '%s'", dst, src), ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+
+	promotedState := make(map[string]core.Secret, len(dstState))
+	for k, secret := range dstState {
+		promotedState[k] = secret
+	}
+	core.ApplyChangesToState(promotedState, changes)
+
+	formatValue, err := newValueFormatter(h.cryptService, project.ID, cmd.Bool("reveal"))
+	if err != nil {
+		return err
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("Promote %s → %s", src, dst), ui.StyleOptions{
+		Color:  "82", // Light green
+		Bold:   true,
+		Margin: []int{0, 0, 1, 0}, // Bottom margin
+	})
+	ui.NewDiffRenderer(h.slate).RenderDiff(core.DiffStates(dstState, promotedState), formatValue)
+
+	if cmd.Bool("dry-run") {
+		return nil
+	}
+
+	if !cmd.Bool("yes") {
+		answer := h.slate.PromptWithDefault(fmt.Sprintf("\nStage these changes in '%s'? (y/N)", dst), "n")
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			h.slate.WriteIndentedText("Promotion cancelled", ui.StyleOptions{
+				Color:  "248", // Gray
+				Italic: true,
+			})
+			return nil
+		}
+	}
+
+	if err := stageChanges(h.secretService, h.changeRecordService, dst, changes); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Staged %d change(s) in '%s'", len(changes), dst))
+	h.slate.WriteIndentedText(fmt.Sprintf("Run `%s env use %s` and `%s commit` to record them", core.AppName, dst, core.AppName), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+	})
+	return nil
+}
+
+// promotionChanges builds add/modify changes for the keys whose plaintext differs
+// between the source and destination. Values are re-encrypted with a fresh nonce
+// so that environments never share ciphertexts.
+func promotionChanges(cryptService cryptService, key []byte, srcState, dstState map[string]core.Secret, keys []string) ([]core.Change, error) {
+	var changes []core.Change
+	for _, k := range keys {
+		secret, exists := srcState[k]
+		if !exists {
+			return nil, fmt.Errorf("'%s' is not committed in the source environment", k)
+		}
+
+		plaintext, err := decryptSecret(cryptService, key, secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", k, err)
+		}
+
+		changeType := core.ChangeTypeAdd
+		if current, exists := dstState[k]; exists {
+			currentPlaintext, err := decryptSecret(cryptService, key, current)
+			if err == nil && currentPlaintext == plaintext && current.NoSecret == secret.NoSecret {
+				continue
+			}
+			changeType = core.ChangeTypeModify
+		}
+
+		change := core.Change{Type: changeType, Key: k, Value: plaintext, NoSecret: secret.NoSecret}
+		if !secret.NoSecret {
+			change.Value, change.Nonce, err = cryptService.Encrypt(key, plaintext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt %s: %w", k, err)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// decryptSecret returns the plaintext of a secret; no-secret values are stored as is
+func decryptSecret(cryptService cryptService, key []byte, secret core.Secret) (string, error) {
+	if secret.Nonce == "" {
+		return secret.Value, nil
+	}
+	return cryptService.Decrypt(key, secret.Value, secret.Nonce)
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/remote"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Pull struct {
+	projectService      projectService
+	envService          envService
+	secretService       secretService
+	commitService       commitService
+	changeRecordService changeRecordService
+	cryptService        cryptService
+	userService         userService
+	apiClient           apiClient
+	slate               slate
+	// bundle applies commits from a bundle on top of the local HEAD, leaving
+	// them unpushed and the remote tracking untouched
+	bundle bool
+}
+
+func NewPullHandler(
+	projectService projectService,
+	envService envService,
+	secretService secretService,
+	commitService commitService,
+	changeRecordService changeRecordService,
+	cryptService cryptService,
+	userService userService,
+	apiClient apiClient,
+	slate slate,
+) *Pull {
+	return &Pull{
+		projectService:      projectService,
+		envService:          envService,
+		secretService:       secretService,
+		commitService:       commitService,
+		changeRecordService: changeRecordService,
+		cryptService:        cryptService,
+		userService:         userService,
+		apiClient:           apiClient,
+		slate:               slate,
+	}
+}
+
+// HandleFetch downloads remote commits without changing the local history
+func (h *Pull) HandleFetch(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	fetched, err := h.fetch(env)
+	if err != nil {
+		return err
+	}
+
+	if len(fetched.Commits) == 0 {
+		h.slate.WriteColoredText("Already up to date.", "")
+		return nil
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Fetched %d new commit(s) for '%s' (remote HEAD: %s)", len(fetched.Commits), env, fetched.Head))
+	h.slate.WriteIndentedText(fmt.Sprintf("Run `%s pull` to integrate them", core.AppName), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+	})
+	return nil
+}
+
+// Handle fetches remote commits and fast-forwards the local history to them.
+// When local commits were made in the meantime it stops, unless --merge or
+// --rebase says how to integrate them.
+func (h *Pull) Handle(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	switch {
+	case cmd.Bool("continue"):
+		return h.continueRebase(env)
+	case cmd.Bool("abort"):
+		return h.abortRebase(env)
+	}
+
+	if err := h.refuseDuringRebase(env); err != nil {
+		return err
+	}
+
+	fetched, err := h.fetch(env)
+	if err != nil {
+		return err
+	}
+
+	if len(fetched.Commits) == 0 {
+		return h.upToDate(env, *fetched)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead != fetched.BaseCommit {
+		switch {
+		case cmd.Bool("merge"):
+			return h.merge(env, *fetched, cmd)
+		case cmd.Bool("rebase"):
+			return h.rebase(env, *fetched)
+		}
+		return h.reportDivergence(env, *fetched)
+	}
+
+	return h.fastForward(env, *fetched)
+}
+
+// withClient returns a copy of the handler that fetches through client
+func (h *Pull) withClient(client apiClient) *Pull {
+	pull := *h
+	pull.apiClient = client
+	return &pull
+}
+
+// withBundle returns a copy of the handler that applies the bundle behind client
+func (h *Pull) withBundle(client apiClient) *Pull {
+	pull := h.withClient(client)
+	pull.bundle = true
+	return pull
+}
+
+// pullFastForward fetches and fast-forwards env like a plain `pull`
+func (h *Pull) pullFastForward(env string) error {
+	if err := h.refuseDuringRebase(env); err != nil {
+		return err
+	}
+
+	fetched, err := h.fetch(env)
+	if err != nil {
+		return err
+	}
+	if len(fetched.Commits) == 0 {
+		return h.upToDate(env, *fetched)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.LocalHead != fetched.BaseCommit {
+		return h.reportDivergence(env, *fetched)
+	}
+	return h.fastForward(env, *fetched)
+}
+
+// refuseDuringRebase fails while a rebase of env awaits --continue or --abort
+func (h *Pull) refuseDuringRebase(env string) error {
+	inProgress, err := h.rebaseInProgress(env)
+	if err != nil {
+		return err
+	}
+	if inProgress {
+		return fmt.Errorf("%w in '%s'; run `%s pull --continue` or `%s pull --abort`", core.ErrRebaseInProgress, env, core.AppName, core.AppName)
+	}
+	return nil
+}
+
+// upToDate finishes a pull that brought no new commits
+func (h *Pull) upToDate(env string, fetched core.FetchedCommits) error {
+	if err := h.settle(env, fetched.Tags); err != nil {
+		return err
+	}
+	h.slate.WriteColoredText("Already up to date.", "")
+	return nil
+}
+
+// settle imports the pulled tags and discards the fetch result. A bundle's tags
+// stay unpushed, and a bundle never replaced the fetch result.
+func (h *Pull) settle(env string, tags []core.Tag) error {
+	if h.bundle {
+		return h.commitService.ImportLocalTags(env, tags)
+	}
+	if err := h.commitService.ImportTags(env, tags); err != nil {
+		return err
+	}
+	return h.commitService.ClearFetched(env)
+}
+
+// reportDivergence explains why fetched commits cannot be fast-forwarded; they
+// stay fetched for `merge`. Bundle commits are not kept, so there is nothing to merge.
+func (h *Pull) reportDivergence(env string, fetched core.FetchedCommits) error {
+	if h.bundle {
+		since, err := h.commitService.CommitsSince(env, fetched.BaseCommit)
+		if err != nil {
+			return fmt.Errorf("failed to get local commits: %w", err)
+		}
+		bundled := make(map[string]bool, len(fetched.Commits))
+		for _, commit := range fetched.Commits {
+			bundled[commit.ID] = true
+		}
+		local := 0
+		for _, commit := range since {
+			if !bundled[commit.ID] {
+				local++
+			}
+		}
+		return fmt.Errorf("'%s' has diverged from the bundle with %d local commit(s); a bundle only fast-forwards, "+
+			"so undo or squash them (or use a bundle made from this history) and apply it again", env, local)
+	}
+
+	local, err := h.commitService.GetCommitsSinceRemoteHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get local commits: %w", err)
+	}
+	h.slate.ShowWarning(fmt.Sprintf(
+		"'%s' has diverged from the remote:\n"+
+			"  %d local commit(s) not pushed\n"+
+			"  %d remote commit(s) not pulled\n"+
+			"The remote commits were fetched; run `%s merge` (or `%s pull --merge`)\n"+
+			"to merge them, or `%s pull --rebase` to replay your commits on top.",
+		env, len(local), len(fetched.Commits), core.AppName, core.AppName, core.AppName))
+	return core.Stopped(core.ErrDiverged)
+}
+
+// fetch downloads the commits after the local remote HEAD and stores them
+func (h *Pull) fetch(env string) (*core.FetchedCommits, error) {
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// The remote is asked for what it has after its tracked HEAD, a bundle for
+	// what it has after the local one
+	since := head.RemoteHead
+	if h.bundle {
+		since = head.LocalHead
+	}
+
+	h.slate.StartSpinner("Fetching remote commits...")
+	response, err := h.apiClient.Pull(remote.PullRequest{
+		ProjectID:   project.ID,
+		Environment: env,
+		SinceCommit: since,
+	})
+	h.slate.StopSpinner()
+	if err != nil {
+		if errors.Is(err, remote.ErrUnauthorized) {
+			return nil, fmt.Errorf("unauthorized access to remote server, please try logging in: %w", err)
+		}
+		return nil, fmt.Errorf("failed to fetch from remote: %w", err)
+	}
+
+	fetched := core.FetchedCommits{
+		BaseCommit: since,
+		Head:       response.Data.CommitHead,
+		Commits:    response.Data.Commits,
+		Tags:       response.Data.Tags,
+		FetchedAt:  time.Now(),
+	}
+	if fetched.Head == "" {
+		fetched.Head = since
+	}
+	if h.bundle {
+		return &fetched, nil
+	}
+	if err := h.commitService.SaveFetched(env, fetched); err != nil {
+		return nil, err
+	}
+	return &fetched, nil
+}
+
+// fastForward integrates fetched commits, keeping uncommitted changes on top
+func (h *Pull) fastForward(env string, fetched core.FetchedCommits) error {
+	pending, err := h.changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		return fmt.Errorf("failed to get pending changes: %w", err)
+	}
+	if overlap := overlappingKeys(pending, fetched.Commits); len(overlap) > 0 {
+		return fmt.Errorf("uncommitted changes to %s would be overwritten by the pull; commit, stash or reset them first",
+			strings.Join(overlap, ", "))
+	}
+
+	oldState, err := committedState(h.commitService, env)
+	if err != nil {
+		return err
+	}
+
+	advance := h.commitService.FastForward
+	if h.bundle {
+		advance = h.commitService.FastForwardLocal
+	}
+	if err := advance(env, fetched); err != nil {
+		return err
+	}
+
+	newState, err := h.commitService.ComputeState(env, fetched.Head)
+	if err != nil {
+		return fmt.Errorf("failed to compute pulled state: %w", err)
+	}
+
+	working := make(map[string]core.Secret, len(newState))
+	for k, secret := range newState {
+		working[k] = secret
+	}
+	core.ApplyChangesToState(working, pending)
+	if err := h.secretService.ReplaceSecrets(env, working); err != nil {
+		return fmt.Errorf("failed to update secrets: %w", err)
+	}
+
+	if err := h.settle(env, fetched.Tags); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Fast-forwarded '%s' to %s (%d commit(s))", env, fetched.Head, len(fetched.Commits)))
+	ui.NewDiffRenderer(h.slate).RenderDiff(core.DiffStates(oldState, newState), func(core.Secret) string { return maskedValue })
+	return nil
+}
+
+// overlappingKeys returns the keys changed both by pending changes and by commits
+func overlappingKeys(pending []core.Change, commits []core.Commit) []string {
+	pendingKeys := make(map[string]bool, len(pending))
+	for _, change := range pending {
+		pendingKeys[change.Key] = true
+	}
+
+	seen := make(map[string]bool)
+	var overlap []string
+	for _, commit := range commits {
+		for _, change := range commit.Changes {
+			if pendingKeys[change.Key] && !seen[change.Key] {
+				seen[change.Key] = true
+				overlap = append(overlap, change.Key)
+			}
+		}
+	}
+	sort.Strings(overlap)
+	return overlap
+}
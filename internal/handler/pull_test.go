@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/jawahars16/jebi/internal/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// stubRemote answers pulls with a fixed head and tags
+type stubRemote struct {
+	head string
+	tags []core.Tag
+}
+
+func (r *stubRemote) Push(req remote.PushRequest) (remote.PushResponse, error) {
+	return remote.PushResponse{}, nil
+}
+
+func (r *stubRemote) Clone(req remote.CloneRequest) (remote.CloneResponse, error) {
+	return remote.CloneResponse{}, nil
+}
+
+func (r *stubRemote) Pull(req remote.PullRequest) (remote.PullResponse, error) {
+	return remote.PullResponse{Message: "ok", Data: remote.PullResponseData{CommitHead: r.head, Tags: r.tags}}, nil
+}
+
+var pullFlags = []cli.Flag{
+	&cli.BoolFlag{Name: "merge"},
+	&cli.BoolFlag{Name: "rebase"},
+	&cli.BoolFlag{Name: "continue"},
+	&cli.BoolFlag{Name: "abort"},
+}
+
+func TestPullKeepsLocallyDeletedTag(t *testing.T) {
+	store := io.NewMemoryStore()
+	envService := core.NewEnvServiceWithStore(store)
+	commitService := core.NewCommitServiceWithStore(store, nil)
+	projectService := core.NewProjectServiceWithStore(store)
+	client := &stubRemote{}
+	h := NewPullHandler(projectService, envService, core.NewSecretServiceWithStore(store), commitService,
+		core.NewChangeRecordServiceWithStore(store), nil, fixedUser{author: "alice"}, client, &quietSlate{})
+
+	_, err := projectService.SaveProjectConfig("p1", "demo", "", "dev")
+	require.NoError(t, err)
+	require.NoError(t, envService.CreateEnv("dev"))
+	require.NoError(t, envService.SetCurrentEnv("dev"))
+	commit, err := commitService.AddCommit("", "dev", "add A", "alice", []core.Change{{Type: core.ChangeTypeAdd, Key: "A", Value: "1"}}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, commitService.UpdateRemoteHead("dev", commit.ID))
+	client.head = commit.ID
+	client.tags = []core.Tag{{Name: "v1.0", CommitID: commit.ID}}
+
+	require.NoError(t, runActionWithFlags(t, h.Handle, pullFlags))
+	tags, err := commitService.ListTags("dev")
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+
+	require.NoError(t, commitService.DeleteTag("dev", "v1.0"))
+	require.NoError(t, runActionWithFlags(t, h.Handle, pullFlags))
+
+	tags, err = commitService.ListTags("dev")
+	require.NoError(t, err)
+	assert.Empty(t, tags, "Pulling should not bring back a deleted tag")
+	deleted, err := commitService.DeletedTags("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0"}, deleted, "The deletion should still be pushed")
+}
@@ -47,8 +47,20 @@ func (h *Push) Handle(ctx context.Context, cmd *cli.Command) error {
 		return nil
 	}
 
-	// Check if there are any commits to push
-	if len(commitsToPush) == 0 {
+	tags, err := h.commitService.UnpushedTags(currentEnv)
+	if err != nil {
+		h.slate.ShowError(fmt.Sprintf("Failed to get tags: %v", err))
+		return nil
+	}
+
+	deletedTags, err := h.commitService.DeletedTags(currentEnv)
+	if err != nil {
+		h.slate.ShowError(fmt.Sprintf("Failed to get deleted tags: %v", err))
+		return nil
+	}
+
+	// Check if there are any commits or tags to push
+	if len(commitsToPush) == 0 && len(tags) == 0 && len(deletedTags) == 0 {
 		fmt.Println("No new commits to push. Everything up-to-date.")
 		return nil
 	}
@@ -126,6 +138,8 @@ func (h *Push) Handle(ctx context.Context, cmd *cli.Command) error {
 		Commits:        commitsToPush,
 		FinalState:     finalState,
 		RemoteHeadHash: head.RemoteHead,
+		Tags:           tags,
+		DeletedTags:    deletedTags,
 	}
 
 	h.slate.UpdateSpinner("Making push request to remote...")
@@ -153,6 +167,12 @@ func (h *Push) Handle(ctx context.Context, cmd *cli.Command) error {
 			h.slate.ShowWarning(fmt.Sprintf("Push succeeded but failed to update remote HEAD locally: %v", err))
 		}
 	}
+	if err := h.commitService.MarkTagsPushed(currentEnv, tags); err != nil {
+		h.slate.ShowWarning(fmt.Sprintf("Push succeeded but failed to record pushed tags: %v", err))
+	}
+	if err := h.commitService.MarkTagsDeleted(currentEnv, deletedTags); err != nil {
+		h.slate.ShowWarning(fmt.Sprintf("Push succeeded but failed to record deleted tags: %v", err))
+	}
 
 	h.slate.StopSpinner()
 	h.slate.WriteColoredText(response.Message, "")
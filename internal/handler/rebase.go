@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+)
+
+// rebase replays the local commits made since the remote HEAD on top of the
+// fetched remote head, giving them new IDs and parents
+func (h *Pull) rebase(env string, fetched core.FetchedCommits) error {
+	pending, err := h.changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		return fmt.Errorf("failed to get pending changes: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("'%s' has uncommitted changes; commit, stash or reset them before rebasing", env)
+	}
+
+	if err := h.commitService.ImportFetched(env, fetched); err != nil {
+		return err
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	chain, err := h.commitService.GetCommitChain(env, head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to build commit chain: %w", err)
+	}
+
+	start := 0
+	if fetched.BaseCommit != "" {
+		start = -1
+		for i, commit := range chain {
+			if commit.ID == fetched.BaseCommit {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return fmt.Errorf("%w: remote HEAD %s is not an ancestor of the local HEAD; cannot rebase", core.ErrUnrelatedHistories, fetched.BaseCommit)
+		}
+	}
+	local := chain[start:]
+	for _, commit := range local {
+		if commit.MergeParentID != "" {
+			return fmt.Errorf("cannot rebase merge commit %s; use `%s pull` to merge instead", commit.ID, core.AppName)
+		}
+	}
+
+	state := core.RebaseState{
+		OriginalHead:       head.LocalHead,
+		OriginalRemoteHead: head.RemoteHead,
+		Onto:               fetched.Head,
+		Remaining:          local,
+		Tags:               fetched.Tags,
+	}
+	if err := h.commitService.SaveRebaseState(env, state); err != nil {
+		return err
+	}
+	if err := h.commitService.UpdateLocalHead(env, fetched.Head); err != nil {
+		return err
+	}
+	if err := h.commitService.UpdateRemoteHead(env, fetched.Head); err != nil {
+		return err
+	}
+
+	return h.replay(env, state)
+}
+
+// continueRebase commits the staged resolution of the stopped commit and replays the rest
+func (h *Pull) continueRebase(env string) error {
+	state, err := h.commitService.GetRebaseState(env)
+	if err != nil {
+		return err
+	}
+
+	if state.Stopped && len(state.Remaining) > 0 {
+		stopped := state.Remaining[0]
+		pending, err := h.changeRecordService.GetPendingChanges(env)
+		if err != nil {
+			return fmt.Errorf("failed to get pending changes: %w", err)
+		}
+		if len(pending) > 0 {
+			if _, err := h.commitService.ReplayCommit(env, stopped, h.userService.GetCommitAuthor(), pending); err != nil {
+				return fmt.Errorf("failed to commit resolved changes: %w", err)
+			}
+			state.Replayed++
+		}
+		if err := h.changeRecordService.ClearPendingChanges(env); err != nil {
+			return fmt.Errorf("failed to clear pending changes: %w", err)
+		}
+		state.Remaining = state.Remaining[1:]
+		state.Stopped = false
+		if err := h.commitService.SaveRebaseState(env, *state); err != nil {
+			return err
+		}
+	}
+
+	return h.replay(env, *state)
+}
+
+// abortRebase restores the history and secrets from before the rebase
+func (h *Pull) abortRebase(env string) error {
+	state, err := h.commitService.GetRebaseState(env)
+	if err != nil {
+		return err
+	}
+
+	if err := h.commitService.UpdateLocalHead(env, state.OriginalHead); err != nil {
+		return err
+	}
+	if err := h.commitService.UpdateRemoteHead(env, state.OriginalRemoteHead); err != nil {
+		return err
+	}
+	if err := discardUncommitted(h.commitService, h.secretService, h.changeRecordService, env); err != nil {
+		return err
+	}
+	if err := h.commitService.ClearRebaseState(env); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Rebase aborted; '%s' is back at %s", env, state.OriginalHead))
+	return nil
+}
+
+// replay re-applies the remaining commits one by one, stopping at the first conflict
+func (h *Pull) replay(env string, state core.RebaseState) error {
+	for len(state.Remaining) > 0 {
+		commit := state.Remaining[0]
+
+		parentState, err := h.commitService.ComputeState(env, commit.ParentID)
+		if err != nil {
+			return fmt.Errorf("failed to compute state before %s: %w", commit.ID, err)
+		}
+		current, err := committedState(h.commitService, env)
+		if err != nil {
+			return err
+		}
+
+		changes, conflicts := core.ReplayChanges(commit, parentState, current)
+		if len(conflicts) > 0 {
+			return h.stopRebase(env, state, current, changes, conflicts)
+		}
+
+		if len(changes) > 0 {
+			if _, err := h.commitService.ReplayCommit(env, commit, h.userService.GetCommitAuthor(), changes); err != nil {
+				return fmt.Errorf("failed to replay commit %s: %w", commit.ID, err)
+			}
+			state.Replayed++
+		}
+		state.Remaining = state.Remaining[1:]
+		if err := h.commitService.SaveRebaseState(env, state); err != nil {
+			return err
+		}
+	}
+
+	committed, err := committedState(h.commitService, env)
+	if err != nil {
+		return err
+	}
+	if err := h.secretService.ReplaceSecrets(env, committed); err != nil {
+		return fmt.Errorf("failed to update secrets: %w", err)
+	}
+	if err := h.commitService.ImportTags(env, state.Tags); err != nil {
+		return err
+	}
+	if err := h.commitService.ClearFetched(env); err != nil {
+		return err
+	}
+	if err := h.commitService.ClearRebaseState(env); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Rebased %d commit(s) of '%s' onto %s", state.Replayed, env, state.Onto))
+	return nil
+}
+
+// stopRebase stages the conflicting commit's changes for the user to review
+func (h *Pull) stopRebase(env string, state core.RebaseState, current map[string]core.Secret, changes []core.Change, conflicts []string) error {
+	commit := state.Remaining[0]
+
+	if err := h.secretService.ReplaceSecrets(env, current); err != nil {
+		return fmt.Errorf("failed to update secrets: %w", err)
+	}
+	if err := stageChanges(h.secretService, h.changeRecordService, env, changes); err != nil {
+		return err
+	}
+
+	state.Stopped = true
+	if err := h.commitService.SaveRebaseState(env, state); err != nil {
+		return err
+	}
+
+	h.slate.ShowWarning(fmt.Sprintf(
+		"Rebase stopped at %s (%s):\n"+
+			"  %s changed both locally and on the remote.\n"+
+			"The local values are staged. Keep them, change them with `%s set`,\n"+
+			"or take the remote value with `%s restore KEY`, then run\n"+
+			"`%s pull --continue` (or `%s pull --abort` to give up).",
+		commit.ID, commit.Message, strings.Join(conflicts, ", "),
+		core.AppName, core.AppName, core.AppName, core.AppName))
+	h.slate.WriteIndentedText(fmt.Sprintf("%d more commit(s) to replay after this one", len(state.Remaining)-1), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+	})
+	return core.Stopped(fmt.Errorf("rebase stopped on conflicting keys: %s", strings.Join(conflicts, ", ")))
+}
+
+// rebaseInProgress reports whether an environment has an unfinished rebase
+func (h *Pull) rebaseInProgress(env string) (bool, error) {
+	_, err := h.commitService.GetRebaseState(env)
+	if errors.Is(err, core.ErrNoRebase) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ensureNoRebase fails when a rebase is stopped in env, since rewriting history
+// underneath it would leave the rebase state pointing at stale commits
+func ensureNoRebase(commitService commitService, env string) error {
+	_, err := commitService.GetRebaseState(env)
+	if errors.Is(err, core.ErrNoRebase) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w in '%s'; run `%s pull --continue` or `%s pull --abort`", core.ErrRebaseInProgress, env, core.AppName, core.AppName)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Reset struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	projectService      projectService
+	slate               slate
+}
+
+func NewResetHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	projectService projectService,
+	slate slate,
+) *Reset {
+	return &Reset{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		projectService:      projectService,
+		slate:               slate,
+	}
+}
+
+// Handle discards every uncommitted change in the current environment
+func (h *Reset) Handle(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	committed, err := committedState(h.commitService, env)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := h.secretService.ListSecrets(project.ID, env)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	diffs := core.DiffStates(committed, core.SecretsToState(secrets))
+	if len(diffs) == 0 {
+		// Nothing differs on disk, but stale change records may still linger
+		if err := h.changeRecordService.ClearPendingChanges(env); err != nil {
+			return fmt.Errorf("failed to clear pending changes: %w", err)
+		}
+		h.slate.WriteStyledText("No uncommitted changes to discard", ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+
+	if !cmd.Bool("hard") {
+		h.slate.WriteStyledText(fmt.Sprintf("The following uncommitted changes in '%s' will be discarded:", env), ui.StyleOptions{
+			Color:  "178", // Yellow/amber
+			Bold:   true,
+			Margin: []int{0, 0, 1, 0}, // Bottom margin
+		})
+		ui.NewDiffRenderer(h.slate).RenderDiff(diffs, func(core.Secret) string { return maskedValue })
+		answer := h.slate.PromptWithDefault("\nDiscard these changes? (y/N)", "n")
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			h.slate.WriteIndentedText("Reset cancelled", ui.StyleOptions{
+				Color:  "248", // Gray
+				Italic: true,
+			})
+			return nil
+		}
+	}
+
+	if err := h.secretService.ReplaceSecrets(env, committed); err != nil {
+		return fmt.Errorf("failed to restore committed secrets: %w", err)
+	}
+
+	if err := h.changeRecordService.ClearPendingChanges(env); err != nil {
+		return fmt.Errorf("failed to clear pending changes: %w", err)
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Discarded %d uncommitted change(s) in '%s'", len(diffs), env))
+	return nil
+}
+
+// committedState computes the secret state at the local HEAD of an environment
+func committedState(commitService commitService, env string) (map[string]core.Secret, error) {
+	head, err := commitService.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	state, err := commitService.ComputeState(env, head.LocalHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute committed state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Restore struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	projectService      projectService
+	slate               slate
+}
+
+func NewRestoreHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	projectService projectService,
+	slate slate,
+) *Restore {
+	return &Restore{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		projectService:      projectService,
+		slate:               slate,
+	}
+}
+
+// Handle discards uncommitted changes for the given keys only
+func (h *Restore) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s restore KEY...", core.AppName)
+	}
+	keys := cmd.Args().Slice()
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	committed, err := committedState(h.commitService, env)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := h.secretService.ListSecrets(project.ID, env)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	working := core.SecretsToState(secrets)
+
+	var changes []core.Change
+	for _, key := range keys {
+		secret, isCommitted := committed[key]
+		_, isWorking := working[key]
+
+		switch {
+		case isCommitted:
+			changes = append(changes, core.Change{
+				Type:     core.ChangeTypeModify,
+				Key:      key,
+				Value:    secret.Value,
+				Nonce:    secret.Nonce,
+				NoSecret: secret.NoSecret,
+			})
+		case isWorking:
+			// Added since the last commit; restoring means removing it
+			changes = append(changes, core.Change{Type: core.ChangeTypeRemove, Key: key})
+		default:
+			return fmt.Errorf("secret with key '%s' does not exist in '%s'", key, env)
+		}
+	}
+
+	if err := h.secretService.ApplyChanges(env, changes); err != nil {
+		return fmt.Errorf("failed to restore secrets: %w", err)
+	}
+
+	if err := h.changeRecordService.DiscardPendingChanges(env, keys); err != nil {
+		return fmt.Errorf("failed to discard pending changes: %w", err)
+	}
+
+	h.slate.ShowEnvironmentContext(env)
+	for _, key := range keys {
+		h.slate.WriteIndentedText(fmt.Sprintf("Restored '%s' to its committed state", key), ui.StyleOptions{
+			Color: "34", // Green
+			Bold:  true,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Revert struct {
+	envService    envService
+	commitService commitService
+	secretService secretService
+	userService   userService
+	slate         slate
+}
+
+func NewRevertHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	userService userService,
+	slate slate,
+) *Revert {
+	return &Revert{
+		envService:    envService,
+		commitService: commitService,
+		secretService: secretService,
+		userService:   userService,
+		slate:         slate,
+	}
+}
+
+func (h *Revert) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s revert COMMIT", core.AppName)
+	}
+	commitID := cmd.Args().Get(0)
+
+	currentEnv, err := h.envService.GetCurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+	env := currentEnv.Env
+
+	// Reverting on top of uncommitted work would mix the two into one commit
+	if len(currentEnv.Changes) > 0 {
+		h.slate.ShowWarning("You have uncommitted changes.\nCommit them before reverting.")
+		return nil
+	}
+
+	target, err := h.commitService.ResolveCommit(env, commitID)
+	if err != nil {
+		return err
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	parentState, err := h.commitService.ComputeState(env, target.ParentID)
+	if err != nil {
+		return fmt.Errorf("failed to compute parent state: %w", err)
+	}
+
+	currentState, err := h.commitService.ComputeState(env, head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to compute current state: %w", err)
+	}
+
+	changes := core.InverseChanges(*target, parentState, currentState)
+	if len(changes) == 0 {
+		h.slate.WriteStyledText(fmt.Sprintf("Nothing to revert: changes from %s are no longer present", target.ID), ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+
+	if err := h.secretService.ApplyChanges(env, changes); err != nil {
+		return fmt.Errorf("failed to apply reverted changes: %w", err)
+	}
+
+	msg := cmd.String("message")
+	if msg == "" {
+		msg = fmt.Sprintf("Revert %q", target.Message)
+	}
+
+	author := h.userService.GetCommitAuthor()
+	commit, err := h.commitService.AddCommit("", env, msg, author, changes, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create revert commit: %w", err)
+	}
+	warnReusedCommit(h.slate, commit, msg, author)
+
+	head, err = h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	renderer := ui.NewCommitRenderer(h.slate)
+	renderer.RenderSingleCommit(*commit, head)
+	return nil
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Rollback struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	projectService      projectService
+	slate               slate
+}
+
+func NewRollbackHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	projectService projectService,
+	slate slate,
+) *Rollback {
+	return &Rollback{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		projectService:      projectService,
+		slate:               slate,
+	}
+}
+
+// Handle stages a key's value from an earlier commit or version as a pending change
+func (h *Rollback) Handle(ctx context.Context, cmd *cli.Command) error {
+	to := cmd.String("to")
+	if cmd.Args().Len() < 1 || to == "" {
+		return fmt.Errorf("usage: %s rollback KEY --to COMMIT|VERSION", core.AppName)
+	}
+	key := cmd.Args().Get(0)
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	commitID, err := h.resolveTarget(env, key, to)
+	if err != nil {
+		return err
+	}
+
+	state, err := h.commitService.ComputeState(env, commitID)
+	if err != nil {
+		return fmt.Errorf("failed to compute state at %s: %w", commitID, err)
+	}
+	target, exists := state[key]
+	if !exists {
+		return fmt.Errorf("'%s' does not exist at commit %s", key, commitID)
+	}
+
+	secrets, err := h.secretService.ListSecrets(project.ID, env)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, current := range secrets {
+		if current.Key == key && current.Value == target.Value && current.Nonce == target.Nonce && current.NoSecret == target.NoSecret {
+			h.slate.ShowWarning(fmt.Sprintf("'%s' already has the value from %s", key, commitID))
+			return nil
+		}
+	}
+
+	// The change is relative to HEAD, whatever is pending for the key
+	committed, err := committedState(h.commitService, env)
+	if err != nil {
+		return err
+	}
+	changeType := core.ChangeTypeModify
+	if _, exists := committed[key]; !exists {
+		changeType = core.ChangeTypeAdd
+	}
+
+	secret := core.Secret{Value: target.Value, Nonce: target.Nonce, NoSecret: target.NoSecret}
+	if _, err := h.secretService.SetSecret(key, env, secret); err != nil {
+		return fmt.Errorf("failed to set secret '%s' in '%s': %w", key, env, err)
+	}
+	if err := h.changeRecordService.AddChangeRecord(env, string(changeType), key, secret.Value, secret.Nonce, secret.NoSecret); err != nil {
+		return fmt.Errorf("failed to record change: %w", err)
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Rolled back '%s' to its value at %s in '%s'", key, commitID, env))
+	h.slate.WriteIndentedText(fmt.Sprintf("Run `%s commit` to record the rollback", core.AppName), ui.StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+	})
+	return nil
+}
+
+// resolveTarget returns the commit of a version number from `jebi history`, or
+// else of a tag or commit ID. Numbers are read as versions before any lookup.
+func (h *Rollback) resolveTarget(env, key, ref string) (string, error) {
+	number, err := strconv.Atoi(ref)
+	if err != nil {
+		commit, err := h.commitService.ResolveCommit(env, ref)
+		if err != nil {
+			return "", err
+		}
+		return commit.ID, nil
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	chain, err := h.commitService.GetCommitChain(env, head.LocalHead)
+	if err != nil {
+		return "", fmt.Errorf("failed to build commit chain: %w", err)
+	}
+
+	versions := core.KeyHistory(chain, key)
+	if number < 1 || number > len(versions) {
+		return "", fmt.Errorf("'%s' has %d version(s); see `%s history %s`", key, len(versions), core.AppName, key)
+	}
+	version := versions[number-1]
+	if version.Change.Type == core.ChangeTypeRemove {
+		return "", fmt.Errorf("version %d of '%s' is a removal; pick an earlier version", number, key)
+	}
+	return version.Commit.ID, nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/io"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+var rollbackFlags = []cli.Flag{&cli.StringFlag{Name: "to"}}
+
+// newRollbackFixture commits A=1, A=2, then removes A and adds B
+func newRollbackFixture(t *testing.T) (*Rollback, core.Commit, func() []core.Change, func() map[string]core.Secret) {
+	t.Helper()
+	store := io.NewMemoryStore()
+	envService := core.NewEnvServiceWithStore(store)
+	commitService := core.NewCommitServiceWithStore(store, nil)
+	secretService := core.NewSecretServiceWithStore(store)
+	changeRecordService := core.NewChangeRecordServiceWithStore(store)
+	projectService := core.NewProjectServiceWithStore(store)
+	h := NewRollbackHandler(envService, commitService, secretService, changeRecordService, projectService, &quietSlate{})
+
+	_, err := projectService.SaveProjectConfig("p1", "demo", "", "dev")
+	require.NoError(t, err)
+	require.NoError(t, envService.CreateEnv("dev"))
+	require.NoError(t, envService.SetCurrentEnv("dev"))
+
+	var commits []core.Commit
+	for i, changes := range [][]core.Change{
+		{{Type: core.ChangeTypeAdd, Key: "A", Value: "1", NoSecret: true}},
+		{{Type: core.ChangeTypeModify, Key: "A", Value: "2", NoSecret: true}},
+		{{Type: core.ChangeTypeRemove, Key: "A"}, {Type: core.ChangeTypeAdd, Key: "B", Value: "3", NoSecret: true}},
+	} {
+		commit, err := commitService.AddCommit("", "dev", "change", "alice", changes, time.Now().Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		commits = append(commits, *commit)
+	}
+	state, err := commitService.ComputeState("dev", commits[2].ID)
+	require.NoError(t, err)
+	require.NoError(t, secretService.ReplaceSecrets("dev", state))
+
+	pending := func() []core.Change {
+		changes, err := changeRecordService.GetPendingChanges("dev")
+		require.NoError(t, err)
+		return changes
+	}
+	working := func() map[string]core.Secret {
+		secrets, err := secretService.ListSecrets("p1", "dev")
+		require.NoError(t, err)
+		return core.SecretsToState(secrets)
+	}
+	return h, commits[0], pending, working
+}
+
+func TestRollbackToVersionNumber(t *testing.T) {
+	h, _, pending, working := newRollbackFixture(t)
+
+	require.NoError(t, runActionWithFlags(t, h.Handle, rollbackFlags, "--to", "1", "A"))
+
+	assert.Equal(t, "1", working()["A"].Value)
+	changes := pending()
+	require.Len(t, changes, 1)
+	assert.Equal(t, core.ChangeTypeAdd, changes[0].Type, "A key missing at HEAD should be added back")
+
+	assert.Error(t, runActionWithFlags(t, h.Handle, rollbackFlags, "--to", "3", "A"), "Version 3 of A is its removal")
+	assert.Error(t, runActionWithFlags(t, h.Handle, rollbackFlags, "--to", "4", "A"))
+}
+
+func TestRollbackToCommit(t *testing.T) {
+	h, first, pending, working := newRollbackFixture(t)
+
+	assert.Error(t, runActionWithFlags(t, h.Handle, rollbackFlags, "--to", first.ID, "B"), "B did not exist at the commit")
+	assert.Empty(t, pending())
+
+	require.NoError(t, runActionWithFlags(t, h.Handle, rollbackFlags, "--to", first.ID, "A"))
+	assert.Equal(t, "1", working()["A"].Value)
+}
@@ -10,16 +10,28 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
+// workspaceReleaseKey holds the function that releases the workspace lock
+type workspaceReleaseKey struct{}
+
+// WithWorkspaceRelease returns a context carrying the function that releases
+// the workspace lock, so a command handing over to a long-running child process
+// does not keep other commands waiting
+func WithWorkspaceRelease(ctx context.Context, release func() error) context.Context {
+	return context.WithValue(ctx, workspaceReleaseKey{}, release)
+}
+
 type Run struct {
 	envService     envService
+	commitService  commitService
 	cryptService   cryptService
 	projectService projectService
 	slate          slate
 }
 
-func NewRunHandler(envService envService, cryptService cryptService, projectService projectService, slate slate) *Run {
+func NewRunHandler(envService envService, commitService commitService, cryptService cryptService, projectService projectService, slate slate) *Run {
 	return &Run{
 		envService:     envService,
+		commitService:  commitService,
 		cryptService:   cryptService,
 		projectService: projectService,
 		slate:          slate,
@@ -42,9 +54,16 @@ func (h *Run) Handle(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to get current environment: %w", err)
 	}
 
-	secrets, err := h.cryptService.LoadSecrets(project.ID, currentEnv)
+	secrets, err := loadSecrets(h.commitService, h.cryptService, project.ID, currentEnv, cmd.String("at"))
 	if err != nil {
-		return fmt.Errorf("failed to load secrets: %w", err)
+		return err
+	}
+
+	// The child may run for hours, and may itself run jebi in this workspace
+	if release, ok := ctx.Value(workspaceReleaseKey{}).(func() error); ok {
+		if err := release(); err != nil {
+			return err
+		}
 	}
 
 	// Build the full shell command string
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Show struct {
+	envService     envService
+	commitService  commitService
+	projectService projectService
+	cryptService   cryptService
+	slate          slate
+}
+
+func NewShowHandler(
+	envService envService,
+	commitService commitService,
+	projectService projectService,
+	cryptService cryptService,
+	slate slate,
+) *Show {
+	return &Show{
+		envService:     envService,
+		commitService:  commitService,
+		projectService: projectService,
+		cryptService:   cryptService,
+		slate:          slate,
+	}
+}
+
+func (h *Show) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s show COMMIT", core.AppName)
+	}
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	project, err := h.projectService.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	commit, err := h.commitService.ResolveCommit(env, cmd.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	parentState, err := h.commitService.ComputeState(env, commit.ParentID)
+	if err != nil {
+		return fmt.Errorf("failed to compute parent state: %w", err)
+	}
+
+	formatValue, err := newValueFormatter(h.cryptService, project.ID, cmd.Bool("reveal"))
+	if err != nil {
+		return err
+	}
+
+	renderer := ui.NewCommitRenderer(h.slate).WithSignatureVerification(project.TrustedKeys)
+	renderer.RenderCommit(*commit, head, false)
+	if commit.ParentID != "" {
+		h.slate.WriteIndentedText(fmt.Sprintf("Parent: %s", commit.ParentID), ui.StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+	renderer.RenderChangeDetails(commit.Changes, parentState, formatValue)
+
+	if cmd.Bool("state") {
+		state, err := h.commitService.ComputeState(env, commit.ID)
+		if err != nil {
+			return fmt.Errorf("failed to compute state at %s: %w", commit.ID, err)
+		}
+		renderer.RenderState(state, formatValue)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Squash struct {
+	envService    envService
+	commitService commitService
+	userService   userService
+	slate         slate
+}
+
+func NewSquashHandler(envService envService, commitService commitService, userService userService, slate slate) *Squash {
+	return &Squash{
+		envService:    envService,
+		commitService: commitService,
+		userService:   userService,
+		slate:         slate,
+	}
+}
+
+// Handle combines a range of unpushed commits into a single commit
+func (h *Squash) Handle(ctx context.Context, cmd *cli.Command) error {
+	fromRef, toRef, ok := strings.Cut(cmd.Args().Get(0), "..")
+	if cmd.Args().Len() != 1 || !ok || fromRef == "" {
+		return fmt.Errorf("usage: %s squash <from>..[<to>]", core.AppName)
+	}
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+	if err := ensureNoRebase(h.commitService, env); err != nil {
+		return err
+	}
+
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	from, err := h.commitService.ResolveCommit(env, fromRef)
+	if err != nil {
+		return err
+	}
+	toID := head.LocalHead
+	if toRef != "" {
+		to, err := h.commitService.ResolveCommit(env, toRef)
+		if err != nil {
+			return err
+		}
+		toID = to.ID
+	}
+	if from.ID == toID {
+		return fmt.Errorf("%s..%s covers a single commit; use `%s commit --amend` to reword it", fromRef, toRef, core.AppName)
+	}
+
+	commit, err := h.commitService.SquashCommits(env, from.ID, toID, cmd.String("message"), h.userService.GetCommitAuthor(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to squash commits: %w", err)
+	}
+
+	head, err = h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	h.slate.WriteStyledText(fmt.Sprintf("Squashed %s..%s into %s", from.ID, toID, commit.ID), ui.StyleOptions{
+		Color:  "34", // Green
+		Bold:   true,
+		Margin: []int{0, 0, 1, 0}, // Bottom margin
+	})
+	renderer := ui.NewCommitRenderer(h.slate)
+	renderer.RenderSingleCommit(*commit, head)
+	return nil
+}
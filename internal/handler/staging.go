@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+)
+
+// stageChanges applies changes to an environment's secrets file and records them
+// as pending changes, recomputing add/modify against the environment's current state
+func stageChanges(secretService secretService, changeRecordService changeRecordService, env string, changes []core.Change) error {
+	for _, change := range changes {
+		if change.Type == core.ChangeTypeRemove {
+			if err := secretService.RemoveSecret(change.Key, env); err != nil {
+				if errors.Is(err, core.ErrSecretNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to remove secret '%s' from '%s': %w", change.Key, env, err)
+			}
+			if err := changeRecordService.AddChangeRecord(env, string(core.ChangeTypeRemove), change.Key, "", "", false); err != nil {
+				return fmt.Errorf("failed to record change: %w", err)
+			}
+			continue
+		}
+
+		secret := core.Secret{Value: change.Value, Nonce: change.Nonce, NoSecret: change.NoSecret}
+		action, err := secretService.SetSecret(change.Key, env, secret)
+		if err != nil {
+			return fmt.Errorf("failed to set secret '%s' in '%s': %w", change.Key, env, err)
+		}
+		if err := changeRecordService.AddChangeRecord(env, string(action), change.Key, secret.Value, secret.Nonce, secret.NoSecret); err != nil {
+			return fmt.Errorf("failed to record change: %w", err)
+		}
+	}
+	return nil
+}
+
+// discardUncommitted restores an environment's secrets file to its committed
+// state and clears its pending changes
+func discardUncommitted(commitService commitService, secretService secretService, changeRecordService changeRecordService, env string) error {
+	committed, err := committedState(commitService, env)
+	if err != nil {
+		return err
+	}
+	if err := secretService.ReplaceSecrets(env, committed); err != nil {
+		return fmt.Errorf("failed to restore '%s' to its committed state: %w", env, err)
+	}
+	if err := changeRecordService.ClearPendingChanges(env); err != nil {
+		return fmt.Errorf("failed to clear pending changes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Stash struct {
+	envService          envService
+	commitService       commitService
+	secretService       secretService
+	changeRecordService changeRecordService
+	stashService        stashService
+	slate               slate
+}
+
+func NewStashHandler(
+	envService envService,
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	stashService stashService,
+	slate slate,
+) *Stash {
+	return &Stash{
+		envService:          envService,
+		commitService:       commitService,
+		secretService:       secretService,
+		changeRecordService: changeRecordService,
+		stashService:        stashService,
+		slate:               slate,
+	}
+}
+
+// HandlePush shelves the pending changes of the current environment
+func (h *Stash) HandlePush(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	entry, err := stashPendingChanges(h.commitService, h.secretService, h.changeRecordService, h.stashService, env, cmd.String("message"))
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		h.slate.WriteStyledText("No local changes to stash", ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Saved %d change(s) in '%s': %s", len(entry.Changes), env, entry.Message))
+	return nil
+}
+
+// HandleList lists all stash entries, newest first
+func (h *Stash) HandleList(ctx context.Context, cmd *cli.Command) error {
+	entries, err := h.stashService.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		h.slate.WriteStyledText("No stash entries", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	for i, entry := range entries {
+		h.slate.WriteStyledText(fmt.Sprintf("stash@{%d}: [%s] %s", i, entry.Env, entry.Message), ui.StyleOptions{
+			Color: "15", // White
+			Bold:  true,
+		})
+		h.slate.WriteIndentedText(fmt.Sprintf("%d change(s), %s", len(entry.Changes), entry.CreatedAt.Format("Mon Jan 2 15:04:05 2006")), ui.StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+	return nil
+}
+
+// HandlePop re-applies a stash entry to the current environment and drops it
+func (h *Stash) HandlePop(ctx context.Context, cmd *cli.Command) error {
+	index, err := parseStashIndex(cmd.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.stashService.Get(index)
+	if err != nil {
+		return err
+	}
+
+	currentEnv, err := h.envService.GetCurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+	env := currentEnv.Env
+	if entry.Env != env {
+		return fmt.Errorf("stash@{%d} was created in '%s'; switch with `%s env use %s` first", index, entry.Env, core.AppName, entry.Env)
+	}
+
+	if !cmd.Bool("force") {
+		conflicts, err := h.conflicts(entry, currentEnv.Changes)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			h.slate.ShowWarning(fmt.Sprintf(
+				"stash@{%d} conflicts with changes made since it was created:\n  %s\n"+
+					"Use --force to overwrite them with the stashed values.",
+				index, strings.Join(conflicts, "\n  ")))
+			return nil
+		}
+	}
+
+	if err := stageChanges(h.secretService, h.changeRecordService, env, entry.Changes); err != nil {
+		return err
+	}
+
+	if err := h.stashService.Drop(index); err != nil {
+		return err
+	}
+
+	h.slate.ShowEnvironmentContext(env)
+	h.slate.WriteStatus(entry.Changes)
+	h.slate.ShowSuccess(fmt.Sprintf("Restored stash@{%d}: %s", index, entry.Message))
+	return nil
+}
+
+// HandleDrop discards a stash entry without applying it
+func (h *Stash) HandleDrop(ctx context.Context, cmd *cli.Command) error {
+	index, err := parseStashIndex(cmd.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.stashService.Get(index)
+	if err != nil {
+		return err
+	}
+
+	if err := h.stashService.Drop(index); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Dropped stash@{%d}: %s", index, entry.Message))
+	return nil
+}
+
+// conflicts compares the stash base with the current HEAD and pending changes
+func (h *Stash) conflicts(entry *core.StashEntry, pending []core.Change) ([]string, error) {
+	baseState, err := h.commitService.ComputeState(entry.Env, entry.BaseCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute state at stash base %s (use --force to apply anyway): %w", entry.BaseCommit, err)
+	}
+
+	headState, err := committedState(h.commitService, entry.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.StashConflicts(*entry, baseState, headState, pending), nil
+}
+
+// stashPendingChanges pushes the pending changes of an environment onto the
+// stash and restores its committed state. It returns nil when there is nothing to stash.
+func stashPendingChanges(
+	commitService commitService,
+	secretService secretService,
+	changeRecordService changeRecordService,
+	stashService stashService,
+	env, message string,
+) (*core.StashEntry, error) {
+	changes, err := changeRecordService.GetPendingChanges(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	head, err := commitService.GetHead(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("WIP on %s", env)
+		if head.LocalHead != "" {
+			message = fmt.Sprintf("WIP on %s: %s", env, head.LocalHead)
+		}
+	}
+
+	entry := core.StashEntry{
+		Env:        env,
+		BaseCommit: head.LocalHead,
+		Message:    message,
+		CreatedAt:  time.Now(),
+		Changes:    changes,
+	}
+	if err := stashService.Push(entry); err != nil {
+		return nil, err
+	}
+
+	if err := discardUncommitted(commitService, secretService, changeRecordService, env); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// parseStashIndex accepts "", "N" or "stash@{N}" and returns N
+func parseStashIndex(ref string) (int, error) {
+	if ref == "" {
+		return 0, nil
+	}
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(ref, "stash@{"), "}")
+	index, err := strconv.Atoi(trimmed)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid stash reference '%s'", ref)
+	}
+	return index, nil
+}
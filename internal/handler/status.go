@@ -4,28 +4,41 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
 	"github.com/urfave/cli/v3"
 )
 
 type Status struct {
-	envService envService
-	slate      slate
+	envService          envService
+	commitService       commitService
+	changeRecordService changeRecordService
+	slate               slate
 }
 
-func NewStatusHandler(envService envService, slate slate) *Status {
+func NewStatusHandler(envService envService, commitService commitService, changeRecordService changeRecordService, slate slate) *Status {
 	return &Status{
-		envService: envService,
-		slate:      slate,
+		envService:          envService,
+		commitService:       commitService,
+		changeRecordService: changeRecordService,
+		slate:               slate,
 	}
 }
 
 func (h *Status) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("all") {
+		return h.handleAll()
+	}
+
 	currentEnv, err := h.envService.GetCurrentEnv()
 	if err != nil {
 		return err
 	}
 
 	in := fmt.Sprintf("On environment %s", currentEnv.Env)
+	if tracking := h.tracking(currentEnv.Env); tracking != "" {
+		in += "\n" + tracking
+	}
 	if len(currentEnv.Changes) == 0 {
 		in += "\n(no pending changes)"
 		fmt.Println(in)
@@ -37,3 +50,69 @@ func (h *Status) Handle(ctx context.Context, cmd *cli.Command) error {
 	h.slate.WriteStatus(currentEnv.Changes)
 	return nil
 }
+
+// handleAll lists pending changes across every environment
+func (h *Status) handleAll() error {
+	envs, err := h.envService.ListEnvs()
+	if err != nil {
+		return err
+	}
+
+	current, err := h.envService.CurrentEnv()
+	if err != nil {
+		return err
+	}
+
+	for i, env := range envs {
+		changes, err := h.changeRecordService.GetPendingChanges(env)
+		if err != nil {
+			return fmt.Errorf("failed to get pending changes for '%s': %w", env, err)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		header := fmt.Sprintf("Environment %s", env)
+		if env == current {
+			header += " (current)"
+		}
+		h.slate.WriteStyledText(header, ui.StyleOptions{
+			Color: "82", // Light green
+			Bold:  true,
+		})
+		if tracking := h.tracking(env); tracking != "" {
+			fmt.Println(tracking)
+		}
+		if len(changes) == 0 {
+			fmt.Println("(no pending changes)")
+			continue
+		}
+		h.slate.WriteStatus(changes)
+	}
+	return nil
+}
+
+// tracking describes how an environment relates to the remote, based on commit
+// ancestry and the last fetch
+func (h *Status) tracking(env string) string {
+	ahead, behind, err := h.commitService.AheadBehind(env)
+	if err != nil {
+		return fmt.Sprintf("Cannot compare with the remote: %v", err)
+	}
+	head, err := h.commitService.GetHead(env)
+	if err != nil {
+		return fmt.Sprintf("Cannot compare with the remote: %v", err)
+	}
+
+	switch {
+	case ahead > 0 && behind > 0:
+		return fmt.Sprintf("Diverged from the remote: %d local and %d remote commit(s)\n(use `%s pull` to integrate them)", ahead, behind, core.AppName)
+	case ahead > 0:
+		return fmt.Sprintf("Ahead of the remote by %d commit(s)\n(use `%s push` to publish them)", ahead, core.AppName)
+	case behind > 0:
+		return fmt.Sprintf("Behind the remote by %d commit(s)\n(use `%s pull` to update)", behind, core.AppName)
+	case head.RemoteHead != "":
+		return "Up to date with the remote"
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+type Tag struct {
+	envService    envService
+	commitService commitService
+	slate         slate
+}
+
+func NewTagHandler(envService envService, commitService commitService, slate slate) *Tag {
+	return &Tag{
+		envService:    envService,
+		commitService: commitService,
+		slate:         slate,
+	}
+}
+
+// Handle creates a tag when a name is given and lists tags otherwise
+func (h *Tag) Handle(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() == 0 {
+		return h.HandleList(ctx, cmd)
+	}
+	if cmd.Args().Len() > 2 {
+		return fmt.Errorf("usage: %s tag NAME [COMMIT]", core.AppName)
+	}
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	tag, err := h.commitService.CreateTag(env, cmd.Args().Get(0), cmd.Args().Get(1), cmd.Bool("force"))
+	if err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Tagged %s as '%s' in '%s'", tag.CommitID, tag.Name, env))
+	return nil
+}
+
+// HandleList lists the tags of the current environment
+func (h *Tag) HandleList(ctx context.Context, cmd *cli.Command) error {
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	tags, err := h.commitService.ListTags(env)
+	if err != nil {
+		return err
+	}
+
+	h.slate.ShowEnvironmentContext(env)
+	if len(tags) == 0 {
+		h.slate.WriteStyledText("No tags", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	for _, tag := range tags {
+		message := "<commit missing>"
+		if commit, err := h.commitService.GetCommit(env, tag.CommitID); err == nil {
+			message = commit.Message
+		}
+		h.slate.WriteIndentedText(fmt.Sprintf("%-16s %s  %s", tag.Name, tag.CommitID, message), ui.StyleOptions{
+			Color: "15", // White
+		})
+	}
+	return nil
+}
+
+// HandleDelete removes a tag from the current environment
+func (h *Tag) HandleDelete(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() < 1 {
+		return fmt.Errorf("usage: %s tag delete NAME", core.AppName)
+	}
+
+	env, err := h.envService.CurrentEnv()
+	if err != nil {
+		return fmt.Errorf("failed to get current environment: %w", err)
+	}
+
+	name := cmd.Args().Get(0)
+	if err := h.commitService.DeleteTag(env, name); err != nil {
+		return err
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Deleted tag '%s'", name))
+	deleted, err := h.commitService.DeletedTags(env)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(deleted, name) {
+		h.slate.WriteIndentedText(fmt.Sprintf("Run `%s push` to delete it on the remote too", core.AppName), ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ type appService interface {
 type projectService interface {
 	SaveProjectConfig(id, name, description, defaultEnvironment string) (string, error)
 	LoadProjectConfig() (*core.Project, error)
+	UpdateProjectConfig(project *core.Project) error
 }
 
 type cryptService interface {
@@ -27,6 +28,8 @@ type cryptService interface {
 	SaveKey(key, project string) error
 	LoadKey(project string) ([]byte, error)
 	LoadKeyWithoutDecoding(project string) (string, error)
+	SealWithPassphrase(passphrase string, plaintext []byte) ([]byte, error)
+	OpenWithPassphrase(passphrase string, sealed []byte) ([]byte, error)
 }
 
 type envService interface {
@@ -36,6 +39,9 @@ type envService interface {
 	SetCurrentEnv(env string) error
 	GetCurrentEnv() (*core.CurrentEnv, error)
 	RemoveEnv(env string) error
+	HasPendingChanges(env string) (bool, error)
+	EnvExists(env string) (bool, error)
+	RenameEnv(oldName, newName string) error
 }
 
 type secretService interface {
@@ -43,11 +49,15 @@ type secretService interface {
 	AddSecret(key, env string, secret core.Secret) error
 	ListSecrets(projectId, env string) ([]core.Secret, error)
 	RemoveSecret(key, env string) error
+	ApplyChanges(env string, changes []core.Change) error
+	ReplaceSecrets(env string, state map[string]core.Secret) error
 }
 
 type changeRecordService interface {
 	AddChangeRecord(env, action, key, value, nonce string, nosecret bool) error
-	ClearPendingChanges() error
+	GetPendingChanges(env string) ([]core.Change, error)
+	ClearPendingChanges(env string) error
+	DiscardPendingChanges(env string, keys []string) error
 }
 
 type userService interface {
@@ -64,26 +74,94 @@ type userService interface {
 type commitService interface {
 	// Commit operations
 	AddCommit(id, env, message, author string, changes []core.Change, timestamp time.Time) (*core.Commit, error)
+	ImportCommit(env string, commit core.Commit) (*core.Commit, error)
+	RelabelCommits(env, oldName string) error
 	GetCommit(env, commitID string) (*core.Commit, error)
+	ResolveCommit(env, ref string) (*core.Commit, error)
 	ListCommits(env string) ([]core.Commit, error)
+	ListHistory(env string) ([]core.Commit, error)
 
 	// HEAD operations
 	GetHead(env string) (*core.Head, error)
 	UpdateLocalHead(env, commitID string) error
 	UpdateRemoteHead(env, commitID string) error
+	ForgetRemote(env string) error
 
 	// Status and state operations
 	ComputeState(env, upToCommitID string) (map[string]core.Secret, error)
 	GetCommitsSinceRemoteHead(env string) ([]core.Commit, error)
+	CommitsSince(env, baseID string) ([]core.Commit, error)
+	AheadBehind(env string) (ahead, behind int, err error)
+	GetCommitChain(env, upToCommitID string) ([]core.Commit, error)
+
+	// Tag operations
+	CreateTag(env, name, ref string, force bool) (*core.Tag, error)
+	ListTags(env string) ([]core.Tag, error)
+	DeleteTag(env, name string) error
+	ImportTags(env string, tags []core.Tag) error
+	ImportLocalTags(env string, tags []core.Tag) error
+	UnpushedTags(env string) ([]core.Tag, error)
+	MarkTagsPushed(env string, tags []core.Tag) error
+	DeletedTags(env string) ([]string, error)
+	MarkTagsDeleted(env string, names []string) error
+
+	// Remote sync operations
+	SaveFetched(env string, fetched core.FetchedCommits) error
+	GetFetched(env string) (*core.FetchedCommits, error)
+	ClearFetched(env string) error
+	FastForward(env string, fetched core.FetchedCommits) error
+	FastForwardLocal(env string, fetched core.FetchedCommits) error
+	ImportFetched(env string, fetched core.FetchedCommits) error
+	MergeBase(env, a, b string) (string, error)
+	GetRebaseState(env string) (*core.RebaseState, error)
+	SaveRebaseState(env string, state core.RebaseState) error
+	ClearRebaseState(env string) error
+	BlameHistory(env, commitID string) ([]core.KeyVersion, error)
+	ReplayCommit(env string, original core.Commit, committer string, changes []core.Change) (*core.Commit, error)
+	AddMergeCommit(env, message, author string, changes []core.Change, mergeParentID string, timestamp time.Time) (*core.Commit, error)
+	AmendCommit(env, message, author string, changes []core.Change, timestamp time.Time) (*core.Commit, error)
+	SquashCommits(env, fromID, toID, message, author string, timestamp time.Time) (*core.Commit, error)
+
+	// Integrity operations
+	CheckHistory(env string) ([]core.IntegrityIssue, error)
+}
+
+type migrationService interface {
+	CurrentVersion() (int, error)
+	Pending() ([]core.Migration, error)
+	Migrate() ([]core.Migration, error)
+}
+
+type reflogService interface {
+	Snapshot() (core.Snapshot, error)
+	Record(command string, before, after core.Snapshot, undoes int) (*core.ReflogEntry, error)
+	List() ([]core.ReflogEntry, error)
+	NextUndo() (*core.ReflogEntry, error)
+	CheckUndo(entry core.ReflogEntry) error
+	Restore(entry core.ReflogEntry) error
+}
+
+type stashService interface {
+	Push(entry core.StashEntry) error
+	List() ([]core.StashEntry, error)
+	Get(index int) (*core.StashEntry, error)
+	Drop(index int) error
+	RenameEnv(oldName, newName string) error
+}
+
+type signingService interface {
+	PublicKey() (string, error)
 }
 
 type apiClient interface {
 	Push(req remote.PushRequest) (remote.PushResponse, error)
 	Clone(req remote.CloneRequest) (remote.CloneResponse, error)
+	Pull(req remote.PullRequest) (remote.PullResponse, error)
 }
 
 type slate interface {
 	PromptWithDefault(message, defaultValue string) string
+	PromptSecret(message string) string
 	ShowHeader(title string)
 	ShowList(title string, items []string, highlight string)
 	WriteStatus(changes []core.Change)
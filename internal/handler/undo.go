@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jawahars16/jebi/internal/core"
+	"github.com/jawahars16/jebi/internal/ui"
+	"github.com/urfave/cli/v3"
+)
+
+// reflogTimeFormat is how reflog entries show when an operation ran
+const reflogTimeFormat = "2006-01-02 15:04:05"
+
+type Reflog struct {
+	reflogService reflogService
+	commitService commitService
+	secretService secretService
+	slate         slate
+}
+
+func NewReflogHandler(reflogService reflogService, commitService commitService, secretService secretService, slate slate) *Reflog {
+	return &Reflog{
+		reflogService: reflogService,
+		commitService: commitService,
+		secretService: secretService,
+		slate:         slate,
+	}
+}
+
+// HandleList shows the recorded operations, newest first
+func (h *Reflog) HandleList(ctx context.Context, cmd *cli.Command) error {
+	entries, err := h.reflogService.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		h.slate.WriteStyledText("No operations recorded yet", ui.StyleOptions{
+			Color:  "248", // Gray
+			Italic: true,
+		})
+		return nil
+	}
+
+	undone := make(map[int]bool)
+	for _, entry := range entries {
+		if entry.Undoes != 0 {
+			undone[entry.Undoes] = true
+		}
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		command := entry.Command
+		if entry.Undoes != 0 {
+			command = fmt.Sprintf("undo #%d", entry.Undoes)
+		}
+		color := lipgloss.Color("15") // White
+		if undone[entry.ID] {
+			command += " (undone)"
+			color = lipgloss.Color("248") // Gray
+		}
+		h.slate.WriteIndentedText(fmt.Sprintf("#%-4d %s  %s", entry.ID, entry.Time.Local().Format(reflogTimeFormat), command), ui.StyleOptions{
+			Color: color,
+			Bold:  true,
+		})
+		for _, line := range describeOperation(entry.Before, entry.After) {
+			h.slate.WriteIndentedText("      "+line, ui.StyleOptions{
+				Color: "248", // Gray
+			})
+		}
+	}
+	return nil
+}
+
+// HandleUndo restores the state before the most recent operation that has not been undone
+func (h *Reflog) HandleUndo(ctx context.Context, cmd *cli.Command) error {
+	entry, err := h.reflogService.NextUndo()
+	if errors.Is(err, core.ErrNothingToUndo) {
+		h.slate.WriteStyledText("Nothing to undo", ui.StyleOptions{
+			Color:   "178", // Yellow/amber
+			Padding: []int{0, 0, 0, 2},
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := h.reflogService.CheckUndo(*entry); err != nil {
+		if !errors.Is(err, core.ErrStateChanged) || !cmd.Bool("force") {
+			if errors.Is(err, core.ErrStateChanged) {
+				return fmt.Errorf("%w; run `%s undo --force` to discard the later changes", err, core.AppName)
+			}
+			return err
+		}
+	}
+
+	before, err := h.reflogService.Snapshot()
+	if err != nil {
+		return err
+	}
+	if err := h.reflogService.Restore(*entry); err != nil {
+		return fmt.Errorf("failed to undo '%s': %w", entry.Command, err)
+	}
+	for env, snapshot := range entry.Before.Envs {
+		if err := h.rebuildSecrets(env, snapshot); err != nil {
+			return err
+		}
+	}
+	after, err := h.reflogService.Snapshot()
+	if err != nil {
+		return err
+	}
+	if _, err := h.reflogService.Record("undo", before, after, entry.ID); err != nil {
+		return fmt.Errorf("undid '%s' but failed to record it: %w", entry.Command, err)
+	}
+
+	h.slate.ShowSuccess(fmt.Sprintf("Undid #%d '%s' from %s", entry.ID, entry.Command, entry.Time.Local().Format(reflogTimeFormat)))
+	for _, line := range describeOperation(before, after) {
+		h.slate.WriteIndentedText(line, ui.StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+	return nil
+}
+
+// rebuildSecrets rewrites an environment's secrets file as its committed state
+// with the pending changes applied on top
+func (h *Reflog) rebuildSecrets(env string, snapshot core.EnvSnapshot) error {
+	state, err := h.commitService.ComputeState(env, snapshot.Head.LocalHead)
+	if err != nil {
+		return fmt.Errorf("failed to compute state of '%s': %w", env, err)
+	}
+	core.ApplyChangesToState(state, snapshot.Pending)
+	if err := h.secretService.ReplaceSecrets(env, state); err != nil {
+		return fmt.Errorf("failed to restore secrets of '%s': %w", env, err)
+	}
+	return nil
+}
+
+// describeOperation summarizes what changed between two snapshots, one line per change
+func describeOperation(before, after core.Snapshot) []string {
+	var lines []string
+	if before.CurrentEnv != after.CurrentEnv {
+		lines = append(lines, fmt.Sprintf("current environment: %s -> %s", orNone(before.CurrentEnv), orNone(after.CurrentEnv)))
+	}
+
+	envs := make(map[string]bool)
+	for env := range before.Envs {
+		envs[env] = true
+	}
+	for env := range after.Envs {
+		envs[env] = true
+	}
+	names := make([]string, 0, len(envs))
+	for env := range envs {
+		names = append(names, env)
+	}
+	sort.Strings(names)
+
+	for _, env := range names {
+		old, existed := before.Envs[env]
+		now, exists := after.Envs[env]
+		switch {
+		case !existed:
+			lines = append(lines, fmt.Sprintf("%s: created", env))
+			continue
+		case !exists:
+			lines = append(lines, fmt.Sprintf("%s: removed", env))
+			continue
+		}
+
+		var changes []string
+		if old.Head.LocalHead != now.Head.LocalHead {
+			changes = append(changes, fmt.Sprintf("HEAD %s -> %s", orNone(old.Head.LocalHead), orNone(now.Head.LocalHead)))
+		}
+		if old.Head.RemoteHead != now.Head.RemoteHead {
+			changes = append(changes, fmt.Sprintf("remote HEAD %s -> %s", orNone(old.Head.RemoteHead), orNone(now.Head.RemoteHead)))
+		}
+		if len(old.Pending) != len(now.Pending) {
+			changes = append(changes, fmt.Sprintf("%d -> %d pending change(s)", len(old.Pending), len(now.Pending)))
+		} else if len(now.Pending) > 0 && fmt.Sprint(old.Pending) != fmt.Sprint(now.Pending) {
+			changes = append(changes, "pending changes updated")
+		}
+		if len(old.Tags) != len(now.Tags) {
+			changes = append(changes, fmt.Sprintf("%d -> %d tag(s)", len(old.Tags), len(now.Tags)))
+		}
+		if len(changes) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", env, strings.Join(changes, ", ")))
+		}
+	}
+
+	if len(before.Stash) != len(after.Stash) {
+		lines = append(lines, fmt.Sprintf("stash: %d -> %d entries", len(before.Stash), len(after.Stash)))
+	}
+	return lines
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
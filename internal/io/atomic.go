@@ -0,0 +1,144 @@
+package io
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFileAtomic replaces a file by writing a temporary file next to it and
+// renaming it into place, so readers see either the old or the new content and
+// never a partial write. The previous content is journaled first when a
+// journal is active.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := journalFile(path, false); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, perm)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %q: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write %q: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to flush %q: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %q: %w", tmpPath, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %q: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %q: %w", path, err)
+	}
+	committed = true
+	return nil
+}
+
+// AppendFile appends data to a file, creating it if needed. With a journal
+// active only the file's prior size is recorded, so appending to a large log
+// stays cheap and a rollback truncates it.
+func AppendFile(path string, data []byte, perm os.FileMode) error {
+	if err := journalFile(path, true); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to append to %q: %w", path, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to flush %q: %w", path, err)
+	}
+	return file.Close()
+}
+
+// RemoveFile deletes a file, journaling its content first so a rollback
+// brings it back. Missing files are ignored.
+func RemoveFile(path string) error {
+	if err := journalFile(path, false); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %q: %w", path, err)
+	}
+	return nil
+}
+
+// MkdirAll creates a directory and any missing parents, journaling each one it
+// creates so a rollback removes them again
+func MkdirAll(path string, perm os.FileMode) error {
+	var missing []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if _, err := journalDir(opMkdir, missing[i], ""); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// Rename moves a file or directory, journaling the move so a rollback moves it
+// back. The target must not exist.
+func Rename(oldPath, newPath string) error {
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("failed to rename %q: %q already exists", oldPath, newPath)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		return err
+	}
+	if _, err := journalDir(opRename, oldPath, newPath); err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// RemoveAll deletes a file or directory and everything in it. With a journal
+// active it is moved aside instead and only deleted when the journal commits,
+// so a rollback can restore it.
+func RemoveAll(path string) error {
+	if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	aside := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.removed-%d", filepath.Base(path), time.Now().UnixNano()))
+	journaled, err := journalDir(opRemove, path, aside)
+	if err != nil {
+		return err
+	}
+	if !journaled {
+		return os.RemoveAll(path)
+	}
+	return os.Rename(path, aside)
+}
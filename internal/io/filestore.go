@@ -0,0 +1,103 @@
+package io
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// fileStore keeps a project in a directory on disk. File writes are atomic, and
+// file and directory changes are journaled when a journal is active.
+type fileStore struct {
+	root string
+}
+
+// NewFileStore returns a Store backed by the directory at root
+func NewFileStore(root string) *fileStore {
+	return &fileStore{
+		root: root,
+	}
+}
+
+func (s *fileStore) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
+
+func (s *fileStore) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(s.path(name))
+}
+
+func (s *fileStore) ReadAt(name string, buf []byte, offset int64) error {
+	file, err := os.Open(s.path(name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.ReadAt(buf, offset); err != nil {
+		return fmt.Errorf("failed to read %q at %d: %w", name, offset, err)
+	}
+	return nil
+}
+
+func (s *fileStore) WriteFile(name string, data []byte) error {
+	return WriteFileAtomic(s.path(name), data, 0600)
+}
+
+func (s *fileStore) AppendFile(name string, data []byte) error {
+	return AppendFile(s.path(name), data, 0600)
+}
+
+func (s *fileStore) Remove(name string) error {
+	return RemoveFile(s.path(name))
+}
+
+func (s *fileStore) Stat(name string) (StoreEntry, error) {
+	info, err := os.Stat(s.path(name))
+	if err != nil {
+		return StoreEntry{}, err
+	}
+	return storeEntry(info), nil
+}
+
+func (s *fileStore) ReadDir(dir string) ([]StoreEntry, error) {
+	entries, err := os.ReadDir(s.path(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StoreEntry, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, storeEntry(info))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (s *fileStore) MkdirAll(dir string) error {
+	return MkdirAll(s.path(dir), 0700)
+}
+
+func (s *fileStore) Rename(oldName, newName string) error {
+	return Rename(s.path(oldName), s.path(newName))
+}
+
+func (s *fileStore) RemoveAll(name string) error {
+	return RemoveAll(s.path(name))
+}
+
+// storeEntry describes a file; directories report no size, as their size on
+// disk depends on the file system
+func storeEntry(info os.FileInfo) StoreEntry {
+	if info.IsDir() {
+		return StoreEntry{Name: info.Name(), IsDir: true}
+	}
+	return StoreEntry{Name: info.Name(), Size: info.Size()}
+}
@@ -0,0 +1,272 @@
+package io
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrJournalActive = fmt.Errorf("a journal is already active")
+
+// Directory operations recorded in the journal
+const (
+	opMkdir  = "mkdir"  // Path was created
+	opRename = "rename" // Path was moved to Target
+	opRemove = "remove" // Path was moved aside to Target, to be deleted on commit
+)
+
+// journalEntry is the content a file had before the journaled operation first
+// wrote it. Files that were only appended to record their prior size instead.
+// Entries with an Op record a directory operation.
+type journalEntry struct {
+	Path     string      `json:"path"`
+	Existed  bool        `json:"existed"`
+	Content  []byte      `json:"content,omitempty"`
+	Mode     os.FileMode `json:"mode,omitempty"`
+	Appended bool        `json:"appended,omitempty"`
+	Size     int64       `json:"size,omitempty"`
+	Op       string      `json:"op,omitempty"`
+	Target   string      `json:"target,omitempty"`
+}
+
+// journal records the prior content of every file written while it is active,
+// and every directory created, moved or removed, so an interrupted operation
+// can be rolled back as a whole. Entries are undone newest first.
+type journal struct {
+	path    string
+	entries []journalEntry
+	seen    map[string]int // Index into entries by absolute path
+}
+
+var (
+	activeJournal *journal
+	journalMu     sync.Mutex
+)
+
+// BeginJournal starts journaling file and directory changes made through this
+// package to the journal file at path
+func BeginJournal(path string) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if activeJournal != nil {
+		return ErrJournalActive
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("journal %q from an interrupted operation must be recovered first", path)
+	}
+	activeJournal = &journal{path: path, seen: make(map[string]int)}
+	return nil
+}
+
+// CommitJournal ends the active journal, keeping every write made under it
+func CommitJournal() error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if activeJournal == nil {
+		return nil
+	}
+	committed := activeJournal
+	activeJournal = nil
+	if err := os.Remove(committed.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+
+	// Removed directories were only moved aside so a rollback could restore them
+	for _, entry := range committed.entries {
+		if entry.Op == opRemove {
+			if err := os.RemoveAll(entry.Target); err != nil {
+				return fmt.Errorf("failed to remove %q: %w", entry.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RollbackJournal ends the active journal and undoes every change made under
+// it, returning the number of changes undone
+func RollbackJournal() (int, error) {
+	journalMu.Lock()
+	rolledBack := activeJournal
+	activeJournal = nil
+	journalMu.Unlock()
+
+	if rolledBack == nil {
+		return 0, nil
+	}
+	return RecoverJournal(rolledBack.path)
+}
+
+// RecoverJournal rolls back the operation recorded in the journal at path, if
+// any, restoring every file and directory it changed. It returns the number of
+// changes undone.
+func RecoverJournal(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []journalEntry
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return 0, fmt.Errorf("failed to parse journal %q: %w", path, err)
+		}
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := undo(entries[i]); err != nil {
+			return 0, fmt.Errorf("failed to roll back %q: %w", entries[i].Path, err)
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		return 0, fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return len(entries), nil
+}
+
+// undo reverts the change a journal entry recorded
+func undo(entry journalEntry) error {
+	switch entry.Op {
+	case opMkdir:
+		// A directory that still holds files from elsewhere is kept
+		os.Remove(entry.Path)
+		return nil
+	case opRename, opRemove:
+		if _, err := os.Stat(entry.Target); errors.Is(err, os.ErrNotExist) {
+			// Already moved back by an earlier, interrupted recovery
+			return nil
+		}
+		return os.Rename(entry.Target, entry.Path)
+	}
+
+	if entry.Appended {
+		if err := os.Truncate(entry.Path, entry.Size); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if !entry.Existed {
+		if err := os.Remove(entry.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(entry.Path, entry.Content, entry.Mode)
+}
+
+// journalDir records a directory operation in the active journal before it is
+// carried out. Files written afterwards are journaled again, since their path
+// may now name a different file.
+func journalDir(op, path, target string) (bool, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if activeJournal == nil {
+		return false, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	entry := journalEntry{Op: op, Path: abs}
+	if target != "" {
+		if entry.Target, err = filepath.Abs(target); err != nil {
+			return false, fmt.Errorf("failed to resolve %q: %w", target, err)
+		}
+	}
+
+	if err := activeJournal.save(append(append([]journalEntry(nil), activeJournal.entries...), entry)); err != nil {
+		return false, err
+	}
+	activeJournal.seen = make(map[string]int)
+	return true, nil
+}
+
+// save writes entries to the journal file and makes them the journal's entries
+func (j *journal) save(entries []journalEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	// The journal must be on disk before the change it protects is made
+	if err := writeFileAtomic(j.path, data, 0600); err != nil {
+		return err
+	}
+	j.entries = entries
+	return nil
+}
+
+// journalFile saves the current content of path to the active journal before
+// it is first written. For appends only the current size is saved; a later
+// full write of the same file upgrades the entry to hold the content.
+func journalFile(path string, appendOnly bool) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if activeJournal == nil {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+
+	entries := append([]journalEntry(nil), activeJournal.entries...)
+	i, seen := activeJournal.seen[abs]
+	switch {
+	case seen && (appendOnly || !entries[i].Appended):
+		return nil
+	case seen:
+		// Everything past the recorded size was appended by this operation
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			return fmt.Errorf("failed to journal %q: %w", path, err)
+		}
+		entries[i].Appended = false
+		entries[i].Content = content[:entries[i].Size]
+	default:
+		entry, err := priorState(abs, appendOnly)
+		if err != nil {
+			return fmt.Errorf("failed to journal %q: %w", path, err)
+		}
+		i = len(entries)
+		entries = append(entries, entry)
+	}
+
+	if err := activeJournal.save(entries); err != nil {
+		return err
+	}
+	activeJournal.seen[abs] = i
+	return nil
+}
+
+// priorState captures what a rollback needs to restore a file
+func priorState(abs string, appendOnly bool) (journalEntry, error) {
+	entry := journalEntry{Path: abs, Mode: 0600}
+	info, err := os.Stat(abs)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return entry, nil
+	case err != nil:
+		return entry, err
+	}
+
+	entry.Existed, entry.Mode = true, info.Mode().Perm()
+	if appendOnly {
+		entry.Appended, entry.Size = true, info.Size()
+		return entry, nil
+	}
+	entry.Content, err = os.ReadFile(abs)
+	return entry, err
+}
@@ -0,0 +1,179 @@
+package io
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverJournalRollsBackInterruptedWrites(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal")
+	existing := filepath.Join(dir, "sec")
+	created := filepath.Join(dir, "changes")
+	require.NoError(t, os.WriteFile(existing, []byte(`{"A":"1"}`), 0600))
+
+	require.NoError(t, BeginJournal(journalPath))
+	require.NoError(t, WriteJSONToFile(existing, map[string]string{"A": "2"}))
+	require.NoError(t, WriteJSONToFile(created, []string{"A"}))
+	require.NoError(t, WriteJSONToFile(existing, map[string]string{"A": "3"}))
+
+	// Simulate a crash: the journal is never committed
+	activeJournal = nil
+
+	restored, err := RecoverJournal(journalPath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored, "Each file should be journaled once")
+
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, `{"A":"1"}`, string(content), "The first recorded content should be restored")
+	assert.NoFileExists(t, created, "Files the operation created should be removed")
+	assert.NoFileExists(t, journalPath)
+}
+
+func TestCommitJournalKeepsWrites(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal")
+	path := filepath.Join(dir, "sec")
+
+	require.NoError(t, BeginJournal(journalPath))
+	assert.ErrorIs(t, BeginJournal(journalPath), ErrJournalActive)
+	require.NoError(t, WriteJSONToFile(path, "value"))
+	require.NoError(t, CommitJournal())
+	assert.NoFileExists(t, journalPath)
+
+	restored, err := RecoverJournal(journalPath)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+
+	value, err := ReadJSONFile[string](path)
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary files should be left behind")
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	lock, err := TryLock(path)
+	require.NoError(t, err)
+
+	// Locks are per open file description, so a second open contends
+	_, err = TryLock(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, lock.Unlock())
+	lock, err = TryLock(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestRecoverJournalTruncatesAppends(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal")
+	log := filepath.Join(dir, "log")
+	rewritten := filepath.Join(dir, "rewritten")
+	require.NoError(t, os.WriteFile(log, []byte("one\n"), 0600))
+	require.NoError(t, os.WriteFile(rewritten, []byte("one\n"), 0600))
+
+	require.NoError(t, BeginJournal(journalPath))
+	require.NoError(t, AppendFile(log, []byte("two\n"), 0600))
+	require.NoError(t, AppendFile(rewritten, []byte("two\n"), 0600))
+	require.NoError(t, WriteFileAtomic(rewritten, []byte("replaced\n"), 0600))
+	activeJournal = nil
+
+	_, err := RecoverJournal(journalPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "one\n", string(content), "Appended data should be truncated away")
+	content, err = os.ReadFile(rewritten)
+	require.NoError(t, err)
+	assert.Equal(t, "one\n", string(content), "A file rewritten after an append should get its original content back")
+}
+
+func TestRecoverJournalRollsBackDirectoryChanges(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "staging"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staging", "sec"), []byte("staging"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dev"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev", "sec"), []byte("dev"), 0600))
+
+	require.NoError(t, BeginJournal(journalPath))
+	require.NoError(t, Rename(filepath.Join(dir, "staging"), filepath.Join(dir, "qa")))
+	require.NoError(t, WriteFileAtomic(filepath.Join(dir, "qa", "sec"), []byte("qa"), 0600))
+	require.NoError(t, RemoveAll(filepath.Join(dir, "dev")))
+	require.NoError(t, MkdirAll(filepath.Join(dir, "dev", "nested"), 0700))
+	require.NoError(t, WriteFileAtomic(filepath.Join(dir, "dev", "nested", "sec"), []byte("new"), 0600))
+	activeJournal = nil
+
+	_, err := RecoverJournal(journalPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "staging", "sec"))
+	require.NoError(t, err)
+	assert.Equal(t, "staging", string(content), "A renamed directory should be moved back with its original content")
+	assert.NoDirExists(t, filepath.Join(dir, "qa"))
+	content, err = os.ReadFile(filepath.Join(dir, "dev", "sec"))
+	require.NoError(t, err)
+	assert.Equal(t, "dev", string(content), "A removed directory should be restored")
+	assert.NoDirExists(t, filepath.Join(dir, "dev", "nested"), "Created directories should be removed")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "Nothing moved aside should be left behind")
+}
+
+func TestRollbackJournal(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal")
+	path := filepath.Join(dir, "sec")
+	removed := filepath.Join(dir, "removed")
+	require.NoError(t, os.MkdirAll(removed, 0700))
+
+	require.NoError(t, BeginJournal(journalPath))
+	require.NoError(t, WriteFileAtomic(path, []byte("value"), 0600))
+	require.NoError(t, RemoveAll(removed))
+	undone, err := RollbackJournal()
+	require.NoError(t, err)
+	assert.Equal(t, 2, undone)
+	assert.NoFileExists(t, path)
+	assert.DirExists(t, removed)
+	assert.NoFileExists(t, journalPath)
+
+	require.NoError(t, BeginJournal(journalPath))
+	require.NoError(t, RemoveAll(removed))
+	require.NoError(t, CommitJournal())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "A committed removal should delete the directory moved aside")
+}
+
+func TestTryLockShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	first, err := TryLockShared(path)
+	require.NoError(t, err)
+	second, err := TryLockShared(path)
+	require.NoError(t, err, "Shared holders should not exclude each other")
+
+	_, err = TryLock(path)
+	assert.ErrorIs(t, err, ErrLocked, "An exclusive lock should wait for shared holders")
+
+	require.NoError(t, first.Unlock())
+	require.NoError(t, second.Unlock())
+	lock, err := TryLock(path)
+	require.NoError(t, err)
+	_, err = TryLockShared(path)
+	assert.ErrorIs(t, err, ErrLocked, "A shared lock should wait for an exclusive holder")
+	require.NoError(t, lock.Unlock())
+}
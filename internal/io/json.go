@@ -36,7 +36,7 @@ func WriteJSONToFile[T any](path string, data T) error {
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
 
-	if err := os.WriteFile(path, out, 0600); err != nil {
+	if err := WriteFileAtomic(path, out, 0600); err != nil {
 		return fmt.Errorf("failed to write to %q: %w", path, err)
 	}
 	return nil
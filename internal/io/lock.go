@@ -0,0 +1,58 @@
+package io
+
+import (
+	"fmt"
+	"os"
+)
+
+var ErrLocked = fmt.Errorf("locked by another process")
+
+// Lock is an advisory lock held on a lock file. The operating system releases
+// it when the process exits, so a crashed command never leaves a stale lock.
+type Lock struct {
+	file *os.File
+}
+
+// TryLock acquires the lock at path without waiting, failing with ErrLocked
+// when another process holds it
+func TryLock(path string) (*Lock, error) {
+	return acquire(path, false, false)
+}
+
+// WaitLock acquires the lock at path, waiting for other processes to release it
+func WaitLock(path string) (*Lock, error) {
+	return acquire(path, false, true)
+}
+
+// TryLockShared acquires the lock at path alongside other shared holders
+// without waiting, failing with ErrLocked when a process holds it exclusively
+func TryLockShared(path string) (*Lock, error) {
+	return acquire(path, true, false)
+}
+
+// WaitLockShared acquires the lock at path alongside other shared holders,
+// waiting for an exclusive holder to release it
+func WaitLockShared(path string) (*Lock, error) {
+	return acquire(path, true, true)
+}
+
+func acquire(path string, shared, wait bool) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(file, shared, wait); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return l.file.Close()
+}
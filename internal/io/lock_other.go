@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package io
+
+import "os"
+
+// Platforms without file locking run commands unlocked
+func lockFile(file *os.File, shared, wait bool) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,236 @@
+package io
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryStore keeps a project in memory, for tests and for embedding jebi
+// without touching the disk
+type memoryStore struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewMemoryStore returns an empty in-memory Store
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		files: make(map[string][]byte),
+		dirs:  map[string]bool{"": true},
+	}
+}
+
+// clean normalizes a name, the store root being ""
+func clean(name string) string {
+	return strings.Trim(path.Clean("/"+name), "/")
+}
+
+func notExist(op, name string) error {
+	return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// parentExists reports whether the directory holding name exists
+func (s *memoryStore) parentExists(name string) bool {
+	dir := path.Dir(name)
+	if dir == "." {
+		dir = ""
+	}
+	return s.dirs[dir]
+}
+
+func (s *memoryStore) ReadFile(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[clean(name)]
+	if !ok {
+		return nil, notExist("read", name)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryStore) ReadAt(name string, buf []byte, offset int64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[clean(name)]
+	if !ok {
+		return notExist("read", name)
+	}
+	if offset < 0 || offset+int64(len(buf)) > int64(len(data)) {
+		return fmt.Errorf("failed to read %q at %d: short read", name, offset)
+	}
+	copy(buf, data[offset:])
+	return nil
+}
+
+func (s *memoryStore) WriteFile(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = clean(name)
+	if !s.parentExists(name) {
+		return notExist("write", name)
+	}
+	if s.dirs[name] {
+		return fmt.Errorf("failed to write %q: is a directory", name)
+	}
+	s.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memoryStore) AppendFile(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = clean(name)
+	if !s.parentExists(name) {
+		return notExist("append", name)
+	}
+	if s.dirs[name] {
+		return fmt.Errorf("failed to append to %q: is a directory", name)
+	}
+	s.files[name] = append(s.files[name], data...)
+	return nil
+}
+
+func (s *memoryStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, clean(name))
+	return nil
+}
+
+func (s *memoryStore) Stat(name string) (StoreEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name = clean(name)
+	if data, ok := s.files[name]; ok {
+		return StoreEntry{Name: path.Base(name), Size: int64(len(data))}, nil
+	}
+	if s.dirs[name] {
+		return StoreEntry{Name: path.Base(name), IsDir: true}, nil
+	}
+	return StoreEntry{}, notExist("stat", name)
+}
+
+func (s *memoryStore) ReadDir(dir string) ([]StoreEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dir = clean(dir)
+	if !s.dirs[dir] {
+		return nil, notExist("readdir", dir)
+	}
+
+	var entries []StoreEntry
+	for name, data := range s.files {
+		if isChild(dir, name) {
+			entries = append(entries, StoreEntry{Name: path.Base(name), Size: int64(len(data))})
+		}
+	}
+	for name := range s.dirs {
+		if name != "" && isChild(dir, name) {
+			entries = append(entries, StoreEntry{Name: path.Base(name), IsDir: true})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// isChild reports whether name sits directly inside dir
+func isChild(dir, name string) bool {
+	parent := path.Dir(name)
+	if parent == "." {
+		parent = ""
+	}
+	return parent == dir
+}
+
+func (s *memoryStore) MkdirAll(dir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dir = clean(dir); dir != "" && dir != "."; dir = path.Dir(dir) {
+		if _, ok := s.files[dir]; ok {
+			return fmt.Errorf("failed to create %q: a file with that name exists", dir)
+		}
+		s.dirs[dir] = true
+	}
+	return nil
+}
+
+func (s *memoryStore) Rename(oldName, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldName, newName = clean(oldName), clean(newName)
+	if _, ok := s.files[newName]; ok || s.dirs[newName] {
+		return fmt.Errorf("failed to rename %q: %q already exists", oldName, newName)
+	}
+	if !s.parentExists(newName) {
+		return notExist("rename", newName)
+	}
+
+	if data, ok := s.files[oldName]; ok {
+		s.files[newName] = data
+		delete(s.files, oldName)
+		return nil
+	}
+	if !s.dirs[oldName] || oldName == "" {
+		return notExist("rename", oldName)
+	}
+
+	prefix := oldName + "/"
+	for name, data := range s.files {
+		if strings.HasPrefix(name, prefix) {
+			s.files[newName+"/"+strings.TrimPrefix(name, prefix)] = data
+			delete(s.files, name)
+		}
+	}
+	for name := range s.dirs {
+		if strings.HasPrefix(name, prefix) {
+			s.dirs[newName+"/"+strings.TrimPrefix(name, prefix)] = true
+			delete(s.dirs, name)
+		}
+	}
+	delete(s.dirs, oldName)
+	s.dirs[newName] = true
+	return nil
+}
+
+func (s *memoryStore) RemoveAll(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = clean(name)
+	if name == "" {
+		s.files = make(map[string][]byte)
+		s.dirs = map[string]bool{"": true}
+		return nil
+	}
+
+	prefix := name + "/"
+	delete(s.files, name)
+	delete(s.dirs, name)
+	for file := range s.files {
+		if strings.HasPrefix(file, prefix) {
+			delete(s.files, file)
+		}
+	}
+	for dir := range s.dirs {
+		if strings.HasPrefix(dir, prefix) {
+			delete(s.dirs, dir)
+		}
+	}
+	return nil
+}
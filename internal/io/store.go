@@ -0,0 +1,79 @@
+package io
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Store holds the files of a project. Names are slash-separated and relative
+// to the project directory, e.g. "envs/dev/sec". Missing files and
+// directories are reported with errors wrapping fs.ErrNotExist.
+type Store interface {
+	// ReadFile returns the content of a file
+	ReadFile(name string) ([]byte, error)
+	// ReadAt fills buf from a file starting at offset
+	ReadAt(name string, buf []byte, offset int64) error
+	// WriteFile replaces a file, so readers see either the old or the new content
+	WriteFile(name string, data []byte) error
+	// AppendFile appends to a file, creating it if needed
+	AppendFile(name string, data []byte) error
+	// Remove deletes a file; missing files are ignored
+	Remove(name string) error
+
+	// Stat describes a file or directory
+	Stat(name string) (StoreEntry, error)
+	// ReadDir lists a directory sorted by name
+	ReadDir(dir string) ([]StoreEntry, error)
+	// MkdirAll creates a directory and any missing parents
+	MkdirAll(dir string) error
+	// Rename moves a file or directory; the target must not exist
+	Rename(oldName, newName string) error
+	// RemoveAll deletes a file or directory and everything in it
+	RemoveAll(name string) error
+}
+
+// StoreEntry describes a file or directory in a Store
+type StoreEntry struct {
+	Name  string // Base name
+	IsDir bool
+	Size  int64
+}
+
+// ReadJSON decodes a JSON file from a store. Like ReadJSONFile, a missing or
+// empty file yields the zero value.
+func ReadJSON[T any](store Store, name string) (T, error) {
+	var result T
+
+	b, err := store.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read %q: %w", name, err)
+	}
+
+	if len(b) == 0 {
+		return result, nil
+	}
+
+	if err := json.Unmarshal(b, &result); err != nil {
+		return result, fmt.Errorf("failed to parse %q: %w", name, err)
+	}
+
+	return result, nil
+}
+
+// WriteJSON encodes data as indented JSON into a file of a store
+func WriteJSON[T any](store Store, name string, data T) error {
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	if err := store.WriteFile(name, out); err != nil {
+		return fmt.Errorf("failed to write to %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package io
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStores checks that every Store implementation behaves the same
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"file":   func(t *testing.T) Store { return NewFileStore(t.TempDir()) },
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+
+			_, err := store.ReadFile("envs/dev/sec")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+			assert.Error(t, store.WriteFile("envs/dev/sec", []byte("x")), "Writing into a missing directory should fail")
+
+			require.NoError(t, store.MkdirAll("envs/dev"))
+			require.NoError(t, WriteJSON(store, "envs/dev/sec", map[string]string{"A": "1"}))
+			secrets, err := ReadJSON[map[string]string](store, "envs/dev/sec")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"A": "1"}, secrets)
+
+			missing, err := ReadJSON[[]string](store, "envs/dev/changes")
+			require.NoError(t, err, "Missing JSON files read as the zero value")
+			assert.Nil(t, missing)
+
+			require.NoError(t, store.AppendFile("envs/dev/log", []byte("one\n")))
+			require.NoError(t, store.AppendFile("envs/dev/log", []byte("two\n")))
+			buf := make([]byte, 3)
+			require.NoError(t, store.ReadAt("envs/dev/log", buf, 4))
+			assert.Equal(t, "two", string(buf))
+			assert.Error(t, store.ReadAt("envs/dev/log", make([]byte, 10), 4))
+
+			info, err := store.Stat("envs/dev/log")
+			require.NoError(t, err)
+			assert.Equal(t, StoreEntry{Name: "log", Size: 8}, info)
+
+			require.NoError(t, store.MkdirAll("envs/prod"))
+			require.NoError(t, store.WriteFile("envs/current", []byte("dev")))
+			entries, err := store.ReadDir("envs")
+			require.NoError(t, err)
+			assert.Equal(t, []StoreEntry{{Name: "current", Size: 3}, {Name: "dev", IsDir: true}, {Name: "prod", IsDir: true}}, entries)
+
+			assert.Error(t, store.Rename("envs/dev", "envs/prod"), "Renaming onto an existing directory should fail")
+			require.NoError(t, store.Rename("envs/dev", "envs/staging"))
+			data, err := store.ReadFile("envs/staging/log")
+			require.NoError(t, err)
+			assert.Equal(t, "one\ntwo\n", string(data))
+			_, err = store.Stat("envs/dev")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+
+			require.NoError(t, store.Remove("envs/staging/log"))
+			require.NoError(t, store.Remove("envs/staging/log"), "Removing a missing file is not an error")
+			require.NoError(t, store.RemoveAll("envs/staging"))
+			entries, err = store.ReadDir("envs")
+			require.NoError(t, err)
+			assert.Len(t, entries, 2)
+		})
+	}
+}
@@ -2,14 +2,19 @@ package keystore
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 
+	"github.com/jawahars16/jebi/internal/io"
 	"github.com/zalando/go-keyring"
 )
 
+// ErrNotFound is returned by Get when no storage holds the key
+var ErrNotFound = fmt.Errorf("key not found")
+
 // KeyStore provides secure storage for sensitive data
 type KeyStore interface {
 	Set(key string, value interface{}) error
@@ -52,7 +57,7 @@ func (k *keyStore) Set(key string, value interface{}) error {
 	// Try keyring first if enabled
 	if k.useKeyring {
 		if err := k.setKeyring(key, string(data)); err == nil {
-			return nil
+			return k.markKeyring(key)
 		}
 		// Fall back to disk if keyring fails
 	}
@@ -63,20 +68,29 @@ func (k *keyStore) Set(key string, value interface{}) error {
 
 // Get retrieves a value securely
 func (k *keyStore) Get(key string, target interface{}) error {
-	var data string
-	var err error
+	var keyringErr error
 
 	// Try keyring first if enabled
 	if k.useKeyring {
-		data, err = k.getKeyring(key)
+		data, err := k.getKeyring(key)
 		if err == nil {
+			// Keys stored before markers existed get one on first read
+			if err := k.markKeyring(key); err != nil {
+				return err
+			}
 			return json.Unmarshal([]byte(data), target)
 		}
+		keyringErr = err
 		// Fall back to disk if keyring fails
 	}
 
 	// Fallback to disk storage
 	diskData, err := k.getDisk(key)
+	if errors.Is(err, ErrNotFound) && keyringErr != nil && k.inKeyring(key) {
+		// The key is in a keyring that cannot be read right now; reporting it
+		// as missing would let callers replace it
+		return fmt.Errorf("failed to read %s from keyring: %w", key, keyringErr)
+	}
 	if err != nil {
 		return err
 	}
@@ -95,6 +109,9 @@ func (k *keyStore) Delete(key string) error {
 
 	// Try to delete from disk
 	diskErr = k.deleteDisk(key)
+	if err := os.Remove(k.markerPath(key)); err != nil && !os.IsNotExist(err) && diskErr == nil {
+		diskErr = fmt.Errorf("failed to delete keyring marker: %w", err)
+	}
 
 	// Return error only if both failed
 	if keyringErr != nil && diskErr != nil {
@@ -156,7 +173,7 @@ func (k *keyStore) setDisk(key string, data []byte) error {
 	}
 
 	// Write file with restricted permissions
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	if err := io.WriteFileAtomic(filePath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write keystore file: %w", err)
 	}
 
@@ -169,7 +186,7 @@ func (k *keyStore) getDisk(key string) ([]byte, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("key not found: %s", key)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
 		}
 		return nil, fmt.Errorf("failed to read keystore file: %w", err)
 	}
@@ -191,6 +208,32 @@ func (k *keyStore) getDiskPath(key string) string {
 	return filepath.Join(k.workingDir, ".jebi", "keystore", fmt.Sprintf("%s.json", key))
 }
 
+// markerPath returns the path of the file recording that a key was stored in
+// the keyring, which tells a missing key apart from an unreadable keyring
+func (k *keyStore) markerPath(key string) string {
+	return filepath.Join(k.workingDir, ".jebi", "keystore", fmt.Sprintf("%s.keyring", key))
+}
+
+// markKeyring records that key is held by the keyring
+func (k *keyStore) markKeyring(key string) error {
+	if k.inKeyring(key) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(k.markerPath(key)), 0700); err != nil {
+		return fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+	if err := io.WriteFileAtomic(k.markerPath(key), nil, 0600); err != nil {
+		return fmt.Errorf("failed to write keyring marker: %w", err)
+	}
+	return nil
+}
+
+// inKeyring reports whether key was stored in the keyring
+func (k *keyStore) inKeyring(key string) bool {
+	_, err := os.Stat(k.markerPath(key))
+	return err == nil
+}
+
 // isKeyringSupportedPlatform checks if keyring is supported on current platform
 func (k *keyStore) isKeyringSupportedPlatform() bool {
 	switch runtime.GOOS {
@@ -1,6 +1,7 @@
 package keystore
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -115,8 +116,34 @@ func TestKeyStoreOperations(t *testing.T) {
 
 	// Test Get non-existent key
 	err = ks.Get("non_existent", &retrievedValue)
-	if err == nil {
-		t.Error("Expected error when getting non-existent key")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when getting non-existent key, got %v", err)
+	}
+}
+
+func TestKeyStoreUnreadableKeyring(t *testing.T) {
+	ks := &keyStore{
+		serviceName: "jebi-cli-test-unreadable",
+		workingDir:  t.TempDir(),
+		useKeyring:  true,
+	}
+
+	// The key was stored in the keyring, which now fails or no longer has it
+	if err := ks.markKeyring("signing_key"); err != nil {
+		t.Fatalf("Failed to mark key: %v", err)
+	}
+
+	var value string
+	err := ks.Get("signing_key", &value)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("A key known to be in the keyring should not be reported missing, got %v", err)
+	}
+
+	if err := ks.Delete("signing_key"); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	if err := ks.Get("signing_key", &value); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after deletion, got %v", err)
 	}
 }
 
@@ -0,0 +1,70 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/jawahars16/jebi/internal/core"
+)
+
+var (
+	ErrReadOnlyBundle = fmt.Errorf("bundles cannot be pushed to")
+)
+
+// bundleClient answers clone and pull requests from a bundle instead of a
+// server, so applying a bundle goes through the same code paths
+type bundleClient struct {
+	bundle core.Bundle
+}
+
+func NewBundleClient(bundle core.Bundle) *bundleClient {
+	return &bundleClient{
+		bundle: bundle,
+	}
+}
+
+func (c *bundleClient) Push(req PushRequest) (PushResponse, error) {
+	return PushResponse{}, ErrReadOnlyBundle
+}
+
+func (c *bundleClient) Clone(req CloneRequest) (CloneResponse, error) {
+	if req.ProjectSlug != c.bundle.Project.ID {
+		return CloneResponse{}, fmt.Errorf("the bundle holds project %s, not %s", c.bundle.Project.ID, req.ProjectSlug)
+	}
+	if c.bundle.BaseCommit != "" {
+		return CloneResponse{}, fmt.Errorf("%w: a new checkout needs a bundle with the whole history, this one starts after %s",
+			core.ErrIncompleteBundle, c.bundle.BaseCommit)
+	}
+
+	return CloneResponse{
+		Message: "ok",
+		Data: CloneResponseData{
+			Project:     c.bundle.Project,
+			Environment: c.bundle.Environment,
+			Commits:     c.bundle.Commits,
+			Secrets:     c.bundle.Secrets,
+			Tags:        c.bundle.Tags,
+		},
+	}, nil
+}
+
+func (c *bundleClient) Pull(req PullRequest) (PullResponse, error) {
+	if req.ProjectID != c.bundle.Project.ID {
+		return PullResponse{}, fmt.Errorf("the bundle belongs to project %s, not %s", c.bundle.Project.ID, req.ProjectID)
+	}
+	if req.Environment != c.bundle.Environment.Name {
+		return PullResponse{}, fmt.Errorf("the bundle holds environment '%s', not '%s'", c.bundle.Environment.Name, req.Environment)
+	}
+
+	commits, err := c.bundle.CommitsAfter(req.SinceCommit)
+	if err != nil {
+		return PullResponse{}, err
+	}
+	return PullResponse{
+		Message: "ok",
+		Data: PullResponseData{
+			CommitHead: c.bundle.Head,
+			Commits:    commits,
+			Tags:       c.bundle.Tags,
+		},
+	}, nil
+}
@@ -10,6 +10,8 @@ type PushRequest struct {
 	Commits        []core.Commit    `json:"commits"`                  // New commits to push
 	FinalState     []core.Secret    `json:"finalState"`               // Final computed secrets with all metadata
 	RemoteHeadHash string           `json:"remoteHeadHash,omitempty"` // For conflict detection
+	Tags           []core.Tag       `json:"tags,omitempty"`           // Named commits of the environment
+	DeletedTags    []string         `json:"deletedTags,omitempty"`    // Names of tags to delete on the remote
 }
 
 type PushResponse struct {
@@ -37,4 +39,23 @@ type CloneResponseData struct {
 	Environment core.Environment `json:"environment"`
 	Commits     []core.Commit    `json:"commits"`
 	Secrets     []core.Secret    `json:"secrets"`
+	Tags        []core.Tag       `json:"tags,omitempty"`
+}
+
+type PullRequest struct {
+	ProjectID   string `json:"projectId"`
+	Environment string `json:"environment"`
+	SinceCommit string `json:"sinceCommit,omitempty"` // Local remote HEAD; empty to fetch the whole history
+}
+
+type PullResponse struct {
+	Message string           `json:"message"`
+	Code    string           `json:"code"`
+	Data    PullResponseData `json:"data,omitempty"`
+}
+
+type PullResponseData struct {
+	CommitHead string        `json:"commitHead"` // Latest commit on the remote
+	Commits    []core.Commit `json:"commits"`    // Commits after SinceCommit, oldest first
+	Tags       []core.Tag    `json:"tags,omitempty"`
 }
@@ -0,0 +1,50 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	PullEndpoint = "/functions/v1/pull"
+)
+
+var (
+	ErrUnknownCommit = fmt.Errorf("remote does not know the local remote HEAD (unrelated histories)")
+)
+
+func (c *client) Pull(req PullRequest) (PullResponse, error) {
+	url := fmt.Sprintf("%s%s", c.baseURL, PullEndpoint)
+
+	// Serialize request to JSON
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return PullResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Make POST request
+	resp, err := c.post(url, jsonData)
+	if err != nil {
+		return PullResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	// Parse response
+	var pullResponse PullResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pullResponse); err != nil {
+		return PullResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return PullResponse{}, ErrUnauthorized
+		}
+		if resp.StatusCode == http.StatusConflict && pullResponse.Code == "UNKNOWN_COMMIT" {
+			return PullResponse{}, ErrUnknownCommit
+		}
+		return PullResponse{}, fmt.Errorf("pull failed: %s", pullResponse.Message)
+	}
+
+	return pullResponse, nil
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 // CommitRenderer provides shared functionality for rendering commits consistently
 type CommitRenderer struct {
 	slate Slate
+
+	verifySignatures bool
+	trustedKeys      []core.TrustedKey
 }
 
 // Slate interface for commit rendering (using concrete methods we need)
@@ -23,7 +27,17 @@ type Slate interface {
 // NewCommitRenderer creates a new commit renderer
 func NewCommitRenderer(s Slate) *CommitRenderer {
 	return &CommitRenderer{slate: s}
-} // RenderCommit renders a single commit with beautiful styling
+}
+
+// WithSignatureVerification makes the renderer show a signature badge for each
+// commit, verified against the given trusted keys
+func (r *CommitRenderer) WithSignatureVerification(trustedKeys []core.TrustedKey) *CommitRenderer {
+	r.verifySignatures = true
+	r.trustedKeys = trustedKeys
+	return r
+}
+
+// RenderCommit renders a single commit with beautiful styling
 // showSpacing controls whether to add empty line before commit (for multiple commits)
 func (r *CommitRenderer) RenderCommit(commit core.Commit, head *core.Head, showSpacing bool) {
 	// Add spacing between commits if requested
@@ -68,10 +82,26 @@ func (r *CommitRenderer) RenderCommit(commit core.Commit, head *core.Head, showS
 		})
 	}
 
+	// Merge commits list both parents, like git
+	if commit.MergeParentID != "" {
+		r.slate.WriteIndentedText(fmt.Sprintf("Merge: %s %s", commit.ParentID, commit.MergeParentID), StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+
 	// Author
 	r.slate.WriteIndentedText(fmt.Sprintf("Author: %s", commit.Author), StyleOptions{
 		Color: "248", // Gray
 	})
+	if commit.Committer != "" {
+		r.slate.WriteIndentedText(fmt.Sprintf("Committer: %s", commit.Committer), StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+
+	if r.verifySignatures {
+		r.renderSignature(commit)
+	}
 
 	// Date - format relative time
 	timeAgo := r.formatTimeAgo(commit.Timestamp)
@@ -94,6 +124,34 @@ func (r *CommitRenderer) RenderCommit(commit core.Commit, head *core.Head, showS
 	}
 }
 
+// renderSignature shows whether the commit signature is valid and from a trusted key
+func (r *CommitRenderer) renderSignature(commit core.Commit) {
+	status, trusted := core.VerifyCommitSignature(commit, r.trustedKeys)
+
+	var (
+		text  string
+		color lipgloss.Color
+	)
+	switch status {
+	case core.SignatureVerified:
+		text = fmt.Sprintf("✔ Verified signature from %s", trusted.Name)
+		color = "34" // Green
+	case core.SignatureUntrusted:
+		text = fmt.Sprintf("? Unverified: signed by untrusted key %s", core.KeyFingerprint(commit.SignerKey))
+		color = "178" // Yellow/amber
+	case core.SignatureInvalid:
+		text = "✘ Invalid signature"
+		color = "196" // Red
+	default:
+		text = "Unsigned"
+		color = "248" // Gray
+	}
+
+	r.slate.WriteIndentedText(fmt.Sprintf("Signature: %s", text), StyleOptions{
+		Color: color,
+	})
+}
+
 // RenderSingleCommit renders a single commit (for use in commit command)
 func (r *CommitRenderer) RenderSingleCommit(commit core.Commit, head *core.Head) {
 	r.slate.WriteStyledText("Created commit:", StyleOptions{
@@ -104,6 +162,70 @@ func (r *CommitRenderer) RenderSingleCommit(commit core.Commit, head *core.Head)
 	r.RenderCommit(commit, head, false)
 }
 
+// RenderChangeDetails renders each change of a commit with its old and new values.
+// parentState provides the old values; formatValue controls masking.
+func (r *CommitRenderer) RenderChangeDetails(changes []core.Change, parentState map[string]core.Secret, formatValue func(core.Secret) string) {
+	r.slate.WriteStyledText("Changes:", StyleOptions{
+		Color:  "15", // White
+		Bold:   true,
+		Margin: []int{1, 0, 0, 0}, // Top margin
+	})
+
+	sorted := append([]core.Change(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	for _, change := range sorted {
+		newValue := core.Secret{Key: change.Key, Value: change.Value, Nonce: change.Nonce, NoSecret: change.NoSecret}
+		old, hadOld := parentState[change.Key]
+
+		switch change.Type {
+		case core.ChangeTypeAdd:
+			r.slate.WriteIndentedText(fmt.Sprintf("+ %s = %s", change.Key, formatValue(newValue)), StyleOptions{
+				Color: "34", // Green
+			})
+		case core.ChangeTypeModify:
+			oldText := "(none)"
+			if hadOld {
+				oldText = formatValue(old)
+			}
+			r.slate.WriteIndentedText(fmt.Sprintf("~ %s: %s → %s", change.Key, oldText, formatValue(newValue)), StyleOptions{
+				Color: "214", // Orange
+			})
+		case core.ChangeTypeRemove:
+			oldText := "(none)"
+			if hadOld {
+				oldText = formatValue(old)
+			}
+			r.slate.WriteIndentedText(fmt.Sprintf("- %s = %s", change.Key, oldText), StyleOptions{
+				Color: "131", // Red
+			})
+		}
+	}
+}
+
+// RenderState renders a full secret state, one key per line sorted by key
+func (r *CommitRenderer) RenderState(state map[string]core.Secret, formatValue func(core.Secret) string) {
+	r.slate.WriteStyledText(fmt.Sprintf("State (%d secrets):", len(state)), StyleOptions{
+		Color:  "15", // White
+		Bold:   true,
+		Margin: []int{1, 0, 0, 0}, // Top margin
+	})
+
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r.slate.WriteIndentedText(fmt.Sprintf("%s = %s", key, formatValue(state[key])), StyleOptions{
+			Color: "248", // Gray
+		})
+	}
+}
+
 // displayChangesWithColors shows changes with appropriate colors for each type
 func (r *CommitRenderer) displayChangesWithColors(changes []core.Change) {
 	var adds, mods, dels int
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jawahars16/jebi/internal/core"
+)
+
+// DiffRenderer provides shared functionality for rendering secret differences
+type DiffRenderer struct {
+	slate Slate
+}
+
+// NewDiffRenderer creates a new diff renderer
+func NewDiffRenderer(s Slate) *DiffRenderer {
+	return &DiffRenderer{slate: s}
+}
+
+// RenderDiff renders each difference on its own line followed by a summary.
+// formatValue controls how secret values are displayed (masked or revealed).
+func (r *DiffRenderer) RenderDiff(diffs []core.SecretDiff, formatValue func(core.Secret) string) {
+	var adds, mods, dels int
+
+	for _, diff := range diffs {
+		switch diff.Type {
+		case core.ChangeTypeAdd:
+			adds++
+			r.slate.WriteIndentedText(fmt.Sprintf("+ %s = %s", diff.Key, formatValue(*diff.New)), StyleOptions{
+				Color: "34", // Green
+			})
+		case core.ChangeTypeModify:
+			mods++
+			r.slate.WriteIndentedText(fmt.Sprintf("~ %s: %s → %s", diff.Key, formatValue(*diff.Old), formatValue(*diff.New)), StyleOptions{
+				Color: "214", // Orange
+			})
+		case core.ChangeTypeRemove:
+			dels++
+			r.slate.WriteIndentedText(fmt.Sprintf("- %s = %s", diff.Key, formatValue(*diff.Old)), StyleOptions{
+				Color: "131", // Red
+			})
+		}
+	}
+
+	var parts []string
+	if adds > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", adds))
+	}
+	if mods > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", mods))
+	}
+	if dels > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", dels))
+	}
+
+	r.slate.WriteStyledText(strings.Join(parts, ", "), StyleOptions{
+		Color:  "248", // Gray
+		Italic: true,
+		Margin: []int{1, 0, 0, 0}, // Top margin
+	})
+}
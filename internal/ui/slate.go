@@ -12,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/list"
 	"github.com/jawahars16/jebi/internal/core"
+	"golang.org/x/term"
 )
 
 type slate struct {
@@ -36,6 +37,29 @@ func (s *slate) PromptWithDefault(message, defaultValue string) string {
 	return input
 }
 
+// PromptSecret asks for a value without echoing it when stdin is a terminal
+func (s *slate) PromptSecret(message string) string {
+	fmt.Printf("%s: ", message)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		input, _ := term.ReadPassword(fd)
+		fmt.Println()
+		return string(input)
+	}
+
+	// Read byte by byte so that consecutive prompts each get their own line
+	var input []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 0 || err != nil || b[0] == '\n' {
+			break
+		}
+		input = append(input, b[0])
+	}
+	return strings.TrimRight(string(input), "\r")
+}
+
 func (s *slate) ShowHeader(title string) {
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.ThickBorder()).